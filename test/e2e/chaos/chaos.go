@@ -0,0 +1,38 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chaos
+
+import (
+	"testing"
+
+	"github.com/tvs/kubernetes-service-tests/test/e2e/chaos/features/drain"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/chaos/features/podkill"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+)
+
+// ChaosTests injects faults into the workload cluster one at a time and
+// verifies that it recovers from each. It is only run in chaos mode.
+func ChaosTests(t *testing.T, tc *framework.TestContextType) {
+	builder := framework.NewTestRunner()
+
+	builder.WithSerialSequence(
+		podkill.Feature(t, tc),
+		drain.Feature(t, tc),
+	)
+
+	builder.Runner().Test(t, tc)
+}
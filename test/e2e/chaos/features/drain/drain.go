@@ -0,0 +1,203 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/e2e-framework/klient"
+	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/chaos"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/node"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/testlabels"
+)
+
+const controlPlaneLabel = "node-role.kubernetes.io/control-plane"
+
+type drainKey struct{}
+
+// drain records the node that was drained and the pods evicted from it.
+type drain struct {
+	node    string
+	evicted []corev1.Pod
+}
+
+// Feature returns a test feature that cordons and drains a random worker
+// node and verifies that the workload cluster recovers
+func Feature(t *testing.T, tc *framework.TestContextType) features.Feature {
+	builder := features.New("node drain")
+	builder.WithLabel(testlabels.WorkloadCluster())
+	builder.WithLabel(testlabels.Chaos())
+	builder.WithLabel(testlabels.Disruptive())
+
+	builder.Setup(func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		nodes, err := node.ListSchedulable(ctx, c.Client())
+		if err != nil {
+			t.Fatalf("unable to list schedulable nodes: %s", err)
+		}
+
+		var workers []string
+		for _, n := range nodes {
+			if _, ok := n.Labels[controlPlaneLabel]; !ok {
+				workers = append(workers, n.Name)
+			}
+		}
+		// Draining the only worker would leave its workloads nowhere to go.
+		if len(workers) < 2 {
+			t.Skipf("at least 2 schedulable worker nodes are required, found %d", len(workers))
+		}
+
+		d := &drain{node: workers[chaos.Rand(tc).Intn(len(workers))]}
+		t.Logf("draining node %s", d.node)
+
+		return context.WithValue(ctx, drainKey{}, d)
+	})
+
+	builder.Assess("Node is cordoned", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		d := ctx.Value(drainKey{}).(*drain)
+		if err := setUnschedulable(ctx, c.Client(), d.node, true); err != nil {
+			t.Fatalf("unable to cordon node %s: %s", d.node, err)
+		}
+		return ctx
+	})
+
+	builder.Assess("Node is drained", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		d := ctx.Value(drainKey{}).(*drain)
+
+		var pods corev1.PodList
+		err := c.Client().Resources().List(ctx, &pods,
+			resources.WithFieldSelector(fields.OneTermEqualSelector("spec.nodeName", d.node).String()))
+		if err != nil {
+			t.Fatalf("unable to list pods on node %s: %s", d.node, err)
+		}
+
+		clientset, err := kubernetes.NewForConfig(c.Client().RESTConfig())
+		if err != nil {
+			t.Fatalf("unable to create clientset: %s", err)
+		}
+
+		for i := range pods.Items {
+			p := &pods.Items[i]
+			if chaos.IsMirrorPod(p) || chaos.ControllerKind(p) == "DaemonSet" {
+				continue
+			}
+			if err := evict(ctx, clientset, p); err != nil {
+				t.Errorf("unable to evict pod %s/%s: %s", p.Namespace, p.Name, err)
+				continue
+			}
+			d.evicted = append(d.evicted, *p)
+		}
+
+		return ctx
+	})
+
+	builder.Assess("Workloads recover", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		d := ctx.Value(drainKey{}).(*drain)
+
+		namespaces := []string{"kube-system"}
+		for _, p := range d.evicted {
+			if !slices.Contains(namespaces, p.Namespace) {
+				namespaces = append(namespaces, p.Namespace)
+			}
+		}
+
+		if err := chaos.WaitForRecovery(ctx, c.Client(), d.evicted, namespaces...); err != nil {
+			t.Error(err)
+		}
+		return ctx
+	})
+
+	builder.Assess("Node is uncordoned", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		d := ctx.Value(drainKey{}).(*drain)
+		if err := setUnschedulable(ctx, c.Client(), d.node, false); err != nil {
+			t.Fatalf("unable to uncordon node %s: %s", d.node, err)
+		}
+		return ctx
+	})
+
+	builder.Teardown(func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		d, ok := ctx.Value(drainKey{}).(*drain)
+		if !ok {
+			return ctx
+		}
+
+		if err := setUnschedulable(ctx, c.Client(), d.node, false); err != nil {
+			t.Errorf("unable to uncordon node %s: %s", d.node, err)
+		}
+		return ctx
+	})
+
+	return builder.Feature()
+}
+
+// setUnschedulable cordons or uncordons the node.
+func setUnschedulable(ctx context.Context, c klient.Client, name string, unschedulable bool) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var n corev1.Node
+		if err := c.Resources().Get(ctx, name, "", &n); err != nil {
+			return err
+		}
+		if n.Spec.Unschedulable == unschedulable {
+			return nil
+		}
+		n.Spec.Unschedulable = unschedulable
+		return c.Resources().Update(ctx, &n)
+	})
+}
+
+// evict evicts the pod, retrying for as long as a PodDisruptionBudget
+// prevents it within the configured chaos recovery timeout.
+func evict(ctx context.Context, clientset kubernetes.Interface, p *corev1.Pod) error {
+	eviction := &policyv1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{Name: p.Name, Namespace: p.Namespace},
+	}
+
+	var last error
+	err := wait.For(func(ctx context.Context) (bool, error) {
+		last = clientset.CoreV1().Pods(p.Namespace).EvictV1(ctx, eviction)
+		switch {
+		case last == nil, apierrors.IsNotFound(last):
+			return true, nil
+		case apierrors.IsTooManyRequests(last):
+			return false, nil
+		default:
+			return false, last
+		}
+	},
+		wait.WithContext(ctx),
+		wait.WithTimeout(framework.NewTimeoutContext().ChaosRecovery),
+		wait.WithInterval(framework.PollInterval()),
+		wait.WithImmediate())
+	if err != nil && last != nil {
+		return last
+	}
+	return err
+}
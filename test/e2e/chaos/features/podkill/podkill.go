@@ -0,0 +1,138 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podkill
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/chaos"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/pod"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/testlabels"
+)
+
+// systemPods is the number of random kube-system pods that are killed.
+const systemPods = 3
+
+// controller is a Deployment running the controller of an addon.
+type controller struct {
+	namespace string
+	name      string
+}
+
+// controllers are the addon controllers that are killed if they are
+// installed.
+var controllers = []controller{
+	{"kube-system", "coredns"},
+	{"kube-system", "antrea-controller"},
+	{"kube-system", "calico-kube-controllers"},
+	{"kube-system", "metrics-server"},
+	{"vmware-system-csi", "vsphere-csi-controller"},
+}
+
+// Feature returns a test feature that kills system pods and verifies that
+// the workload cluster recovers
+func Feature(t *testing.T, tc *framework.TestContextType) features.Feature {
+	builder := features.New("pod kill")
+	builder.WithLabel(testlabels.WorkloadCluster())
+	builder.WithLabel(testlabels.Chaos())
+	builder.WithLabel(testlabels.Disruptive())
+
+	builder.Assess("Addon controllers recover", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		var (
+			removed    []corev1.Pod
+			namespaces []string
+		)
+		for _, ctrl := range controllers {
+			var d appsv1.Deployment
+			err := c.Client().Resources().Get(ctx, ctrl.name, ctrl.namespace, &d)
+			switch {
+			case apierrors.IsNotFound(err):
+				t.Logf("deployment %s/%s is not installed, skipping", ctrl.namespace, ctrl.name)
+				continue
+			case err != nil:
+				t.Fatalf("unable to get deployment %s/%s: %s", ctrl.namespace, ctrl.name, err)
+			}
+
+			pods, err := pod.List(ctx, c.Client(), ctrl.namespace, d.Spec.Selector)
+			if err != nil {
+				t.Fatalf("unable to list pods of deployment %s/%s: %s", ctrl.namespace, ctrl.name, err)
+			}
+			removed = append(removed, kill(ctx, t, c, pods)...)
+			namespaces = append(namespaces, ctrl.namespace)
+		}
+
+		if err := chaos.WaitForRecovery(ctx, c.Client(), removed, namespaces...); err != nil {
+			t.Error(err)
+		}
+		return ctx
+	})
+
+	builder.Assess("Random kube-system pods recover", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		var pods corev1.PodList
+		if err := c.Client().Resources("kube-system").List(ctx, &pods); err != nil {
+			t.Fatalf("unable to list pods in kube-system: %s", err)
+		}
+
+		// Only pods recreated by a controller are candidates, static pods
+		// cannot be deleted through the API.
+		var candidates []corev1.Pod
+		for i := range pods.Items {
+			if !chaos.IsMirrorPod(&pods.Items[i]) && chaos.ControllerKind(&pods.Items[i]) != "" {
+				candidates = append(candidates, pods.Items[i])
+			}
+		}
+
+		rng := chaos.Rand(tc)
+		rng.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
+		if len(candidates) > systemPods {
+			candidates = candidates[:systemPods]
+		}
+
+		removed := kill(ctx, t, c, candidates)
+		if err := chaos.WaitForRecovery(ctx, c.Client(), removed, "kube-system"); err != nil {
+			t.Error(err)
+		}
+		return ctx
+	})
+
+	return builder.Feature()
+}
+
+// kill deletes the pods and returns those that were deleted.
+func kill(ctx context.Context, t *testing.T, c *envconf.Config, pods []corev1.Pod) []corev1.Pod {
+	t.Helper()
+
+	var removed []corev1.Pod
+	for i := range pods {
+		p := &pods[i]
+		t.Logf("killing pod %s/%s on node %s", p.Namespace, p.Name, p.Spec.NodeName)
+		if err := c.Client().Resources().Delete(ctx, p); err != nil && !apierrors.IsNotFound(err) {
+			t.Errorf("unable to delete pod %s/%s: %s", p.Namespace, p.Name, err)
+			continue
+		}
+		removed = append(removed, *p)
+	}
+	return removed
+}
@@ -19,22 +19,32 @@ package e2e
 import (
 	"testing"
 
+	"github.com/tvs/kubernetes-service-tests/test/e2e/chaos"
 	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
 	"github.com/tvs/kubernetes-service-tests/test/e2e/sample"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/service"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/upgrade"
 )
 
 func RunE2ETests(t *testing.T, tc *framework.TestContextType) {
+	// Chaos testing is mutually exclusive from other forms of testing
+	if tc.Mode == framework.ModeChaos {
+		chaos.ChaosTests(t, tc)
+		return
+	}
+
+	// Upgrade testing exclusively targets the KubernetesDistribution so it
+	// gets a focused signal
+	if tc.Mode == framework.ModeUpgrade {
+		upgrade.UpgradeTests(t, tc)
+		return
+	}
+
 	// TODO(tvs): Ensure that tests are invoked for the correct context
 	// e.g., service vs workload
 	sample.SampleTests(t, tc)
 
-	// TODO(tvs): Ensure Cluster and TKC tests can be run separately (or
-	// conjoined) so we can pointedly limit which of the slow lifecycle tests we
-	// exercise
-
-	// TODO(tvs): Ensure upgrade tests can be exclusively targeted for
-	// KubernetesDistribution testing
-
-	// TODO(tvs): Chaos testing should be mutually exclusive from other forms
-	// of testing
+	// Cluster, TKC and TKR suites are selected with -service-suites so we can
+	// pointedly limit which of the slow lifecycle tests we exercise
+	service.ServiceTests(t, tc)
 }
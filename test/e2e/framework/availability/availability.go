@@ -0,0 +1,336 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package availability monitors the availability of the cluster under test
+// while disruptive features run. A Monitor polls the API server and a Service
+// inside the cluster in the background and records every window in which
+// either was unavailable. The Service is only deployed to a cluster once a
+// disruptive feature monitors it.
+package availability
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/e2e-framework/klient"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/types"
+
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/testlabels"
+)
+
+const (
+	// Interval is how often each target is probed.
+	Interval = time.Second
+
+	// requestTimeout is how long a single request to the API server may take
+	// before it is considered unavailable.
+	requestTimeout = 5 * time.Second
+)
+
+// Target is something whose availability is monitored.
+type Target string
+
+const (
+	// APIServer is the API server of the cluster, probed through /readyz.
+	APIServer Target = "API"
+
+	// Service is a Service inside the cluster, probed through its ClusterIP
+	// from client pods inside the cluster.
+	Service Target = "Service"
+)
+
+// Window is a period in which a target was unavailable.
+type Window struct {
+	Target Target
+	Start  time.Time
+
+	// End is zero while the target is still unavailable.
+	End time.Time
+}
+
+// Duration returns how long the target was unavailable, up until now if it
+// still is.
+func (w Window) Duration() time.Duration {
+	if w.End.IsZero() {
+		return time.Since(w.Start)
+	}
+	return w.End.Sub(w.Start)
+}
+
+func (w Window) String() string {
+	end := "now"
+	if !w.End.IsZero() {
+		end = w.End.Format(time.TimeOnly)
+	}
+	return fmt.Sprintf("%s unavailable %s (%s-%s)", w.Target, w.Duration().Round(time.Second),
+		w.Start.Format(time.TimeOnly), end)
+}
+
+type monitorKey struct{}
+
+// probeFunc checks the availability of a target once. It returns errUnknown
+// if the availability cannot be determined.
+type probeFunc func(ctx context.Context, m *Monitor) error
+
+// Monitor probes targets in the background and records their outages.
+type Monitor struct {
+	mu      sync.Mutex
+	windows []Window
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// FromContext returns the Monitor running for the current feature, or nil if
+// there is none.
+func FromContext(ctx context.Context) *Monitor {
+	m, _ := ctx.Value(monitorKey{}).(*Monitor)
+	return m
+}
+
+// Hooks start and stop a Monitor around every disruptive feature. They are
+// registered with the test environment:
+//
+//	h := availability.New(tc)
+//	env.BeforeEachFeature(h.Start)
+//	env.AfterEachFeature(h.Stop)
+//	env.Finish(h.Finish)
+type Hooks struct {
+	tc *framework.TestContextType
+
+	mu sync.Mutex
+
+	// services are the probed Services deployed so far, keyed by the host
+	// of the cluster they were deployed to, so that each cluster is only
+	// deployed to once per run.
+	services map[string]*service
+}
+
+// New returns hooks that monitor the cluster under test of each disruptive
+// feature.
+func New(tc *framework.TestContextType) *Hooks {
+	return &Hooks{tc: tc, services: map[string]*service{}}
+}
+
+// Start starts a Monitor for features labeled as disruptive and makes it
+// available to the feature through its context. The cluster under test is
+// the workload cluster for Kubernetes Service features, and the cluster of
+// the test environment otherwise. The probed Service is deployed to it the
+// first time it is monitored.
+func (h *Hooks) Start(ctx context.Context, c *envconf.Config, t *testing.T, f types.Feature) (context.Context, error) {
+	if !f.Labels().Contains(testlabels.Disruptive()) {
+		return ctx, nil
+	}
+
+	cl, err := h.client(ctx, c, f)
+	if err != nil {
+		return ctx, fmt.Errorf("creating client for availability monitor: %w", err)
+	}
+	clientset, err := kubernetes.NewForConfig(cl.RESTConfig())
+	if err != nil {
+		return ctx, fmt.Errorf("creating clientset for availability monitor: %w", err)
+	}
+	rc := clientset.CoreV1().RESTClient()
+
+	probes := map[Target]probeFunc{
+		APIServer: func(ctx context.Context, _ *Monitor) error {
+			ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+			defer cancel()
+			return rc.Get().AbsPath("/readyz").Do(ctx).Error()
+		},
+	}
+
+	// A Service that cannot be deployed only limits what is monitored, so
+	// it does not fail the feature.
+	if s := h.service(ctx, cl); s.err != nil {
+		t.Logf("unable to deploy availability probe, only the API server is monitored: %s", s.err)
+	} else {
+		probes[Service] = s.probe(cl)
+	}
+
+	m := start(ctx, probes)
+	return context.WithValue(ctx, monitorKey{}, m), nil
+}
+
+// client returns a client for the cluster under test of the feature.
+func (h *Hooks) client(ctx context.Context, c *envconf.Config, f types.Feature) (klient.Client, error) {
+	if f.Labels().Contains(testlabels.KubernetesService()) {
+		return h.tc.WorkloadClient(ctx, c.Client())
+	}
+	return c.Client(), nil
+}
+
+// service returns the probed Service of the cluster, deploying it to a
+// namespace of its own if it has not been yet.
+func (h *Hooks) service(ctx context.Context, c klient.Client) *service {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	host := c.RESTConfig().Host
+	if s, ok := h.services[host]; ok {
+		return s
+	}
+
+	s, err := deployService(ctx, c, envconf.RandomName("availability", 20))
+	if err != nil {
+		s.err = err
+	}
+	h.services[host] = s
+	return s
+}
+
+// Stop stops the Monitor of the feature, if any, and logs every outage
+// recorded while the feature ran.
+func (h *Hooks) Stop(ctx context.Context, c *envconf.Config, t *testing.T, f types.Feature) (context.Context, error) {
+	m := FromContext(ctx)
+	if m == nil {
+		return ctx, nil
+	}
+	m.stop()
+
+	for _, w := range m.Windows() {
+		t.Logf("%s during %q", w, f.Name())
+	}
+	return context.WithValue(ctx, monitorKey{}, nil), nil
+}
+
+// Finish deletes the namespaces the probed Services were deployed to. A
+// namespace that cannot be deleted, such as one in a cluster deleted by a
+// feature, does not fail the run.
+func (h *Hooks) Finish(ctx context.Context, c *envconf.Config) (context.Context, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, s := range h.services {
+		s.delete(ctx)
+	}
+	return ctx, nil
+}
+
+func start(ctx context.Context, probes map[Target]probeFunc) *Monitor {
+	// The monitor outlives the step that starts it, so it must not be
+	// cancelled along with the step.
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	m := &Monitor{cancel: cancel}
+
+	for target, request := range probes {
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			m.probe(ctx, target, request)
+		}()
+	}
+
+	return m
+}
+
+// probe polls the target until the context is cancelled, opening a window
+// when it becomes unavailable and closing it once it recovers.
+func (m *Monitor) probe(ctx context.Context, target Target, check probeFunc) {
+	ticker := time.NewTicker(Interval)
+	defer ticker.Stop()
+
+	open := -1
+	defer func() {
+		if open >= 0 {
+			m.mu.Lock()
+			m.windows[open].End = time.Now()
+			m.mu.Unlock()
+		}
+	}()
+
+	for {
+		err := check(ctx, m)
+
+		// A probe interrupted by Stop says nothing about the target.
+		if ctx.Err() != nil {
+			return
+		}
+
+		m.mu.Lock()
+		switch {
+		case errors.Is(err, errUnknown):
+		case err != nil && open < 0:
+			m.windows = append(m.windows, Window{Target: target, Start: time.Now()})
+			open = len(m.windows) - 1
+		case err == nil && open >= 0:
+			m.windows[open].End = time.Now()
+			open = -1
+		}
+		m.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// unavailable reports whether the target is currently unavailable.
+func (m *Monitor) unavailable(target Target) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, w := range m.windows {
+		if w.Target == target && w.End.IsZero() {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Monitor) stop() {
+	m.cancel()
+	m.wg.Wait()
+}
+
+// Windows returns the outages recorded so far in the order they started.
+func (m *Monitor) Windows() []Window {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	windows := make([]Window, len(m.windows))
+	copy(windows, m.windows)
+	return windows
+}
+
+// AssertMaxDowntime checks that the target has not been unavailable for
+// longer than max at a time while the feature has been running. Outages
+// still ongoing count up until now. It is a no-op if no Monitor is running
+// for the feature.
+func AssertMaxDowntime(ctx context.Context, t *testing.T, target Target, max time.Duration) {
+	t.Helper()
+
+	m := FromContext(ctx)
+	if m == nil {
+		t.Logf("availability of %s is not monitored for this feature", target)
+		return
+	}
+
+	for _, w := range m.Windows() {
+		if w.Target == target && w.Duration() > max {
+			t.Errorf("%s, more than the tolerated %s", w, max)
+		}
+	}
+}
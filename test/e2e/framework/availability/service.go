@@ -0,0 +1,180 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package availability
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/e2e-framework/klient"
+
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/deployment"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/pod"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/probe"
+)
+
+const (
+	// serviceName is the name of the Deployment and Service probed by the
+	// Monitor.
+	serviceName = "availability"
+
+	// clientName is the name of the Deployment of pods the Service is probed
+	// from.
+	clientName = "availability-client"
+
+	// replicas of the probe and client Deployments, so that losing a single
+	// node does not make the Service unavailable or leave it unprobed.
+	replicas = 2
+
+	// connectTimeout is how long a single connection to the Service may take
+	// from a client pod, including the exec into it.
+	connectTimeout = 10 * time.Second
+)
+
+// errUnknown is returned by a probe when the availability of its target
+// cannot be determined, so that no window is opened or closed.
+var errUnknown = errors.New("availability is unknown")
+
+// service is the probed Service deployed to a cluster for the test run.
+type service struct {
+	c         klient.Client
+	namespace string
+	clusterIP string
+
+	// err is set if the Service could not be deployed.
+	err error
+}
+
+// deployService creates the namespace and deploys the probe Deployment and
+// Service to it along with the client pods it is probed from, and waits for
+// both Deployments to be available. The returned service is never nil, so
+// that the namespace can be deleted even if deploying to it fails.
+func deployService(ctx context.Context, c klient.Client, namespace string) (*service, error) {
+	ds := &service{c: c, namespace: namespace}
+
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}
+	if err := c.Resources().Create(ctx, ns); err != nil {
+		return ds, fmt.Errorf("creating namespace %s: %w", namespace, err)
+	}
+
+	server := newDeployment(serviceName, namespace)
+	client := newDeployment(clientName, namespace)
+	for _, d := range []*appsv1.Deployment{server, client} {
+		if err := c.Resources().Create(ctx, d); err != nil && !apierrors.IsAlreadyExists(err) {
+			return ds, fmt.Errorf("creating deployment %s/%s: %w", namespace, d.Name, err)
+		}
+	}
+
+	s := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: serviceName, Namespace: namespace},
+		Spec: corev1.ServiceSpec{
+			Selector: selector(serviceName),
+			Ports: []corev1.ServicePort{{
+				Port:       probe.Port,
+				TargetPort: intstr.FromInt32(probe.Port),
+			}},
+		},
+	}
+	if err := c.Resources().Create(ctx, s); err != nil && !apierrors.IsAlreadyExists(err) {
+		return ds, fmt.Errorf("creating service %s/%s: %w", namespace, serviceName, err)
+	}
+	if err := c.Resources().Get(ctx, serviceName, namespace, s); err != nil {
+		return ds, fmt.Errorf("getting service %s/%s: %w", namespace, serviceName, err)
+	}
+
+	for _, d := range []*appsv1.Deployment{server, client} {
+		if err := deployment.WaitForAvailable(ctx, c, d); err != nil {
+			return ds, fmt.Errorf("waiting for deployment %s/%s: %w", namespace, d.Name, err)
+		}
+	}
+
+	ds.clusterIP = s.Spec.ClusterIP
+	return ds, nil
+}
+
+// delete deletes the namespace of the Service along with everything deployed
+// to it, ignoring any error.
+func (s *service) delete(ctx context.Context) {
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: s.namespace}}
+	_ = s.c.Resources().Delete(ctx, ns)
+}
+
+// probe connects to the ClusterIP of the Service from the first ready client
+// pod that succeeds, so that the data plane is probed rather than the API
+// server Service proxy. The client pods are reached through the API server,
+// so the Service is not probed while the API server is unavailable.
+func (s *service) probe(c klient.Client) probeFunc {
+	return func(ctx context.Context, m *Monitor) error {
+		if m.unavailable(APIServer) {
+			return errUnknown
+		}
+
+		listCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+		pods, err := pod.List(listCtx, c, s.namespace, &metav1.LabelSelector{MatchLabels: selector(clientName)})
+		cancel()
+		if err != nil {
+			return errUnknown
+		}
+
+		var last error
+		for i := range pods {
+			p := &pods[i]
+			if !pod.IsReady(p) || p.DeletionTimestamp != nil {
+				continue
+			}
+
+			connCtx, cancel := context.WithTimeout(ctx, connectTimeout)
+			last = probe.Connect(connCtx, c, p, s.clusterIP, probe.Port)
+			cancel()
+			if last == nil {
+				return nil
+			}
+		}
+
+		// Without a ready client, such as while nodes are drained, the
+		// Service cannot be probed.
+		if last == nil {
+			return errUnknown
+		}
+		return last
+	}
+}
+
+// newDeployment returns a Deployment of probe pods whose replicas are spread
+// across nodes, so that a single node going down does not take all of them
+// with it.
+func newDeployment(name, namespace string) *appsv1.Deployment {
+	d := probe.NewDeployment(name, namespace, replicas, selector(name))
+	d.Spec.Template.Spec.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{{
+		MaxSkew:           1,
+		TopologyKey:       corev1.LabelHostname,
+		WhenUnsatisfiable: corev1.ScheduleAnyway,
+		LabelSelector:     &metav1.LabelSelector{MatchLabels: selector(name)},
+	}}
+	return d
+}
+
+func selector(name string) map[string]string {
+	return map[string]string{"app": name}
+}
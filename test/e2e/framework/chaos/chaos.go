@@ -0,0 +1,140 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package chaos contains helpers for injecting faults into a cluster and
+// verifying that it recovers.
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/e2e-framework/klient"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/daemonset"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/deployment"
+)
+
+// Rand returns the source of randomness used to pick fault targets. If the
+// suite is shuffled the shuffle seed is used so that a run can be repeated.
+func Rand(tc *framework.TestContextType) *rand.Rand {
+	seed := time.Now().UnixNano()
+	if tc.Shuffle {
+		seed = tc.ShuffleSeed
+	}
+	return rand.New(rand.NewSource(seed))
+}
+
+// IsMirrorPod reports whether the pod is the API representation of a static
+// pod, which cannot be deleted or evicted through the API.
+func IsMirrorPod(p *corev1.Pod) bool {
+	_, ok := p.Annotations[corev1.MirrorPodAnnotationKey]
+	return ok
+}
+
+// ControllerKind returns the kind of the controller owning the pod, or an
+// empty string if it has none.
+func ControllerKind(p *corev1.Pod) string {
+	for _, ref := range p.OwnerReferences {
+		if ref.Controller != nil && *ref.Controller {
+			return ref.Kind
+		}
+	}
+	return ""
+}
+
+// WaitForRecovery waits within the configured chaos recovery timeout until
+// none of the removed pods exist and every Deployment, StatefulSet and
+// DaemonSet in the namespaces is fully available again.
+func WaitForRecovery(ctx context.Context, c klient.Client, removed []corev1.Pod, namespaces ...string) error {
+	var pending []string
+	err := wait.For(func(ctx context.Context) (bool, error) {
+		pending = unrecovered(ctx, c, removed, namespaces)
+		return len(pending) == 0, nil
+	},
+		wait.WithContext(ctx),
+		wait.WithTimeout(framework.NewTimeoutContext().ChaosRecovery),
+		wait.WithInterval(framework.PollInterval()),
+		wait.WithImmediate())
+	if err != nil {
+		return fmt.Errorf("not recovered: %s: %w", strings.Join(pending, ", "), err)
+	}
+	return nil
+}
+
+// unrecovered returns a description of every removed pod that still exists
+// and every workload in the namespaces that is not available.
+func unrecovered(ctx context.Context, c klient.Client, removed []corev1.Pod, namespaces []string) []string {
+	var pending []string
+
+	for _, p := range removed {
+		var current corev1.Pod
+		err := c.Resources().Get(ctx, p.Name, p.Namespace, &current)
+		switch {
+		case apierrors.IsNotFound(err):
+		case err != nil:
+			pending = append(pending, fmt.Sprintf("pod %s/%s: %s", p.Namespace, p.Name, err))
+		case current.UID == p.UID:
+			pending = append(pending, fmt.Sprintf("pod %s/%s still exists", p.Namespace, p.Name))
+		}
+	}
+
+	for _, ns := range namespaces {
+		var deployments appsv1.DeploymentList
+		if err := c.Resources(ns).List(ctx, &deployments); err != nil {
+			pending = append(pending, fmt.Sprintf("deployments in %s: %s", ns, err))
+		}
+		for i := range deployments.Items {
+			if !deployment.IsAvailable(&deployments.Items[i]) {
+				pending = append(pending, fmt.Sprintf("deployment %s/%s", ns, deployments.Items[i].Name))
+			}
+		}
+
+		var statefulSets appsv1.StatefulSetList
+		if err := c.Resources(ns).List(ctx, &statefulSets); err != nil {
+			pending = append(pending, fmt.Sprintf("statefulsets in %s: %s", ns, err))
+		}
+		for _, s := range statefulSets.Items {
+			desired := int32(1)
+			if s.Spec.Replicas != nil {
+				desired = *s.Spec.Replicas
+			}
+			if s.Status.ObservedGeneration < s.Generation || s.Status.AvailableReplicas != desired {
+				pending = append(pending, fmt.Sprintf("statefulset %s/%s", ns, s.Name))
+			}
+		}
+
+		var daemonSets appsv1.DaemonSetList
+		if err := c.Resources(ns).List(ctx, &daemonSets); err != nil {
+			pending = append(pending, fmt.Sprintf("daemonsets in %s: %s", ns, err))
+		}
+		for i := range daemonSets.Items {
+			if !daemonset.IsAvailable(&daemonSets.Items[i]) {
+				pending = append(pending, fmt.Sprintf("daemonset %s/%s", ns, daemonSets.Items[i].Name))
+			}
+		}
+	}
+
+	return pending
+}
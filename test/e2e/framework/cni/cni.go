@@ -0,0 +1,84 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cni detects which CNI is installed in a workload cluster so that
+// features for other CNIs can be skipped.
+package cni
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/e2e-framework/klient"
+)
+
+const (
+	// Antrea is the name of the Antrea CNI.
+	Antrea = "antrea"
+
+	// Calico is the name of the Calico CNI.
+	Calico = "calico"
+)
+
+// agents maps each known CNI to the DaemonSet that runs its agent in
+// kube-system.
+var agents = map[string]string{
+	Antrea: "antrea-agent",
+	Calico: "calico-node",
+}
+
+// Detect returns the name of the CNI installed in the workload cluster that c
+// is a client for, based on the agent DaemonSets found in kube-system. An
+// error is returned if no known CNI, or more than one, is installed.
+func Detect(ctx context.Context, c klient.Client) (string, error) {
+	var installed []string
+	for name, agent := range agents {
+		var ds appsv1.DaemonSet
+		err := c.Resources().Get(ctx, agent, "kube-system", &ds)
+		switch {
+		case err == nil:
+			installed = append(installed, name)
+		case !apierrors.IsNotFound(err):
+			return "", fmt.Errorf("getting daemonset kube-system/%s: %w", agent, err)
+		}
+	}
+
+	switch len(installed) {
+	case 0:
+		return "", fmt.Errorf("no known CNI found in kube-system")
+	case 1:
+		return installed[0], nil
+	default:
+		return "", fmt.Errorf("multiple CNIs found in kube-system: %v", installed)
+	}
+}
+
+// SkipUnlessInstalled skips the test unless the named CNI is installed in the
+// workload cluster that c is a client for.
+func SkipUnlessInstalled(ctx context.Context, t *testing.T, c klient.Client, name string) {
+	t.Helper()
+
+	installed, err := Detect(ctx, c)
+	if err != nil {
+		t.Fatalf("unable to detect the installed CNI: %s", err)
+	}
+	if installed != name {
+		t.Skipf("%s is not installed, the cluster uses %s", name, installed)
+	}
+}
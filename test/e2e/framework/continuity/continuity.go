@@ -0,0 +1,352 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package continuity verifies that customer workloads survive an upgrade. It
+// provides hooks that deploy stateless and stateful applications protected by
+// PodDisruptionBudgets before an upgrade and verify them after it.
+package continuity
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/e2e-framework/klient"
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/klient/wait/conditions"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/deployment"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/pod"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/probe"
+)
+
+const (
+	stateless = "stateless"
+	stateful  = "stateful"
+
+	statelessReplicas = 3
+	statefulReplicas  = 2
+
+	// maxUnavailable is the number of pods of each application that its
+	// PodDisruptionBudget allows to be disrupted.
+	maxUnavailable = 1
+
+	image     = "registry.k8s.io/e2e-test-images/busybox:1.36.1-1"
+	container = "app"
+	mountPath = "/data"
+	tokenFile = mountPath + "/token"
+)
+
+// ClientFunc returns a client for the cluster the workloads are deployed to.
+type ClientFunc func(ctx context.Context, c *envconf.Config) (klient.Client, error)
+
+// Direct returns the client of the test environment, for when the cluster
+// being upgraded is the cluster under test.
+func Direct(_ context.Context, c *envconf.Config) (klient.Client, error) {
+	return c.Client(), nil
+}
+
+// Hooks deploy workloads before an upgrade and verify them after it. They
+// are used around an upgrade feature by passing Before to its Setup, After
+// to an Assess following the upgrade, and Cleanup to its Teardown:
+//
+//	h := continuity.New(tc, continuity.Direct)
+//	builder.Setup(h.Before)
+//	builder.Assess("Cluster is upgraded", upgrade)
+//	builder.Assess("Workloads are intact", h.After)
+//	builder.Teardown(h.Cleanup)
+type Hooks struct {
+	tc     *framework.TestContextType
+	client ClientFunc
+
+	c         klient.Client
+	namespace string
+
+	// tokens are the contents written to the volume of each stateful pod,
+	// keyed by pod name.
+	tokens map[string]string
+
+	// monitors track the ready pods of each application for the duration of
+	// the upgrade.
+	monitors []*monitor
+}
+
+// New returns hooks that deploy workloads to the cluster returned by client.
+func New(tc *framework.TestContextType, client ClientFunc) *Hooks {
+	return &Hooks{tc: tc, client: client}
+}
+
+// Before deploys the stateless and stateful applications, writes data to the
+// volumes of the stateful application and starts monitoring that their
+// PodDisruptionBudgets are respected.
+func (h *Hooks) Before(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+	cl, err := h.client(ctx, c)
+	if err != nil {
+		t.Fatalf("unable to create client for continuity workloads: %s", err)
+	}
+	h.c = cl
+	h.namespace = envconf.RandomName("continuity", 20)
+
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: h.namespace}}
+	if err := h.c.Resources().Create(ctx, ns); err != nil {
+		t.Fatalf("unable to create namespace %s: %s", h.namespace, err)
+	}
+
+	// Teardown is skipped when Setup fails, so the namespace and its
+	// volumes are deleted here instead.
+	if err := h.deploy(ctx); err != nil {
+		h.Cleanup(ctx, t, c)
+		t.Fatalf("unable to deploy continuity workloads: %s", err)
+	}
+
+	h.monitors = []*monitor{
+		startMonitor(ctx, h.c, h.namespace, stateless, statelessReplicas-maxUnavailable),
+		startMonitor(ctx, h.c, h.namespace, stateful, statefulReplicas-maxUnavailable),
+	}
+
+	return ctx
+}
+
+// deploy creates both applications in the namespace of the hooks, waits for
+// them to be available and writes a token to each stateful volume.
+func (h *Hooks) deploy(ctx context.Context) error {
+	objs := []k8s.Object{
+		probe.NewDeployment(stateless, h.namespace, statelessReplicas, selector(stateless)),
+		newService(stateless, h.namespace, false),
+		newPodDisruptionBudget(stateless, h.namespace),
+		newService(stateful, h.namespace, true),
+		newStatefulSet(h.namespace),
+		newPodDisruptionBudget(stateful, h.namespace),
+	}
+	for _, obj := range objs {
+		if err := h.c.Resources().Create(ctx, obj); err != nil {
+			return fmt.Errorf("creating %T %s/%s: %w", obj, h.namespace, obj.GetName(), err)
+		}
+	}
+
+	if err := h.waitForApps(ctx); err != nil {
+		return err
+	}
+
+	h.tokens = make(map[string]string, statefulReplicas)
+	for i := 0; i < statefulReplicas; i++ {
+		name := fmt.Sprintf("%s-%d", stateful, i)
+		token := envconf.RandomName("token", 16)
+		if _, err := h.exec(ctx, name, "sh", "-c", fmt.Sprintf("echo %s > %s && sync", token, tokenFile)); err != nil {
+			return fmt.Errorf("writing data in pod %s/%s: %w", h.namespace, name, err)
+		}
+		h.tokens[name] = token
+	}
+
+	return nil
+}
+
+// After verifies that the PodDisruptionBudgets were respected during the
+// upgrade, that the data written before it persisted and that the Services
+// still route to their pods.
+func (h *Hooks) After(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+	if h.c == nil {
+		t.Fatal("continuity workloads were not deployed")
+	}
+
+	for _, m := range h.monitors {
+		m.stop()
+		if m.violation != "" {
+			t.Errorf("PodDisruptionBudget %s/%s was not respected: %s", h.namespace, m.app, m.violation)
+		}
+	}
+	h.monitors = nil
+
+	if err := h.waitForApps(ctx); err != nil {
+		t.Fatalf("continuity workloads are not available: %s", err)
+	}
+
+	for name, token := range h.tokens {
+		out, err := h.exec(ctx, name, "cat", tokenFile)
+		switch {
+		case err != nil:
+			t.Errorf("unable to read data in pod %s/%s: %s", h.namespace, name, err)
+		case strings.TrimSpace(out) != token:
+			t.Errorf("data in pod %s/%s did not persist: expected %q, found %q", h.namespace, name, token, strings.TrimSpace(out))
+		}
+	}
+
+	client := probe.NewPod("client", h.namespace, "", map[string]string{"app": "client"})
+	if err := h.c.Resources().Create(ctx, client); err != nil {
+		t.Fatalf("unable to create pod %s/%s: %s", client.Namespace, client.Name, err)
+	}
+	if err := pod.WaitForReady(ctx, h.c, client); err != nil {
+		t.Fatalf("probe client is not ready: %s", err)
+	}
+
+	var svc corev1.Service
+	if err := h.c.Resources().Get(ctx, stateless, h.namespace, &svc); err != nil {
+		t.Fatalf("unable to get service %s/%s: %s", h.namespace, stateless, err)
+	}
+	hosts := []string{
+		svc.Spec.ClusterIP,
+		fmt.Sprintf("%s.%s.svc.%s", stateless, h.namespace, h.tc.ClusterDomain),
+	}
+	for _, host := range hosts {
+		var last error
+		err := wait.For(func(ctx context.Context) (bool, error) {
+			last = probe.Connect(ctx, h.c, client, host, probe.Port)
+			return last == nil, nil
+		},
+			wait.WithContext(ctx),
+			wait.WithTimeout(framework.NewTimeoutContext().PodStart),
+			wait.WithInterval(framework.PollInterval()),
+			wait.WithImmediate())
+		if err != nil {
+			t.Errorf("service %s/%s does not route via %s: %s", h.namespace, stateless, host, last)
+		}
+	}
+
+	return ctx
+}
+
+// Cleanup stops monitoring and deletes the workloads along with their
+// volumes.
+func (h *Hooks) Cleanup(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+	for _, m := range h.monitors {
+		m.stop()
+	}
+	h.monitors = nil
+
+	if h.c == nil {
+		return ctx
+	}
+
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: h.namespace}}
+	if err := h.c.Resources().Delete(ctx, ns); err != nil {
+		t.Errorf("unable to delete namespace %s: %s", h.namespace, err)
+	}
+	return ctx
+}
+
+// waitForApps waits for every replica of both applications to be available.
+func (h *Hooks) waitForApps(ctx context.Context) error {
+	d := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: stateless, Namespace: h.namespace}}
+	if err := deployment.WaitForAvailable(ctx, h.c, d); err != nil {
+		return fmt.Errorf("deployment %s/%s is not available: %w", h.namespace, stateless, err)
+	}
+
+	s := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: stateful, Namespace: h.namespace}}
+	err := wait.For(conditions.New(h.c.Resources()).ResourceMatch(s, func(obj k8s.Object) bool {
+		s := obj.(*appsv1.StatefulSet)
+		return s.Status.ObservedGeneration >= s.Generation && s.Status.AvailableReplicas == statefulReplicas
+	}),
+		wait.WithContext(ctx),
+		wait.WithTimeout(framework.NewTimeoutContext().PodStart+framework.NewTimeoutContext().ClaimProvision),
+		wait.WithInterval(framework.PollInterval()),
+		wait.WithImmediate())
+	if err != nil {
+		return fmt.Errorf("statefulset %s/%s is not available: %w", h.namespace, stateful, err)
+	}
+	return nil
+}
+
+func (h *Hooks) exec(ctx context.Context, name string, cmd ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	if err := h.c.Resources().ExecInPod(ctx, h.namespace, name, container, cmd, &stdout, &stderr); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %s", msg, err)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
+
+func selector(app string) map[string]string {
+	return map[string]string{"app": app}
+}
+
+func newService(app, namespace string, headless bool) *corev1.Service {
+	s := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: app, Namespace: namespace},
+		Spec: corev1.ServiceSpec{
+			Selector: selector(app),
+			Ports: []corev1.ServicePort{{
+				Port:       probe.Port,
+				TargetPort: intstr.FromInt32(probe.Port),
+			}},
+		},
+	}
+	if headless {
+		s.Spec.ClusterIP = corev1.ClusterIPNone
+	}
+	return s
+}
+
+// newPodDisruptionBudget returns a PodDisruptionBudget that allows a single
+// pod of the application to be disrupted at a time.
+func newPodDisruptionBudget(app, namespace string) *policyv1.PodDisruptionBudget {
+	maxUnavailable := intstr.FromInt32(maxUnavailable)
+
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Name: app, Namespace: namespace},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector:       &metav1.LabelSelector{MatchLabels: selector(app)},
+			MaxUnavailable: &maxUnavailable,
+		},
+	}
+}
+
+// newStatefulSet returns a StatefulSet whose pods each mount a volume
+// provisioned from the default StorageClass.
+func newStatefulSet(namespace string) *appsv1.StatefulSet {
+	replicas := int32(statefulReplicas)
+
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: stateful, Namespace: namespace},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:    &replicas,
+			ServiceName: stateful,
+			Selector:    &metav1.LabelSelector{MatchLabels: selector(stateful)},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: selector(stateful)},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:         container,
+						Image:        image,
+						Command:      []string{"sleep", "infinity"},
+						VolumeMounts: []corev1.VolumeMount{{Name: "data", MountPath: mountPath}},
+					}},
+				},
+			},
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{
+				ObjectMeta: metav1.ObjectMeta{Name: "data"},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					Resources: corev1.VolumeResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+					},
+				},
+			}},
+		},
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package continuity
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/e2e-framework/klient"
+
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/pod"
+)
+
+// monitor polls the ready pods of an application in the background and
+// records the first time fewer than minReady were ready. Evictions during an
+// upgrade must respect the PodDisruptionBudget, so the application should
+// never drop below it.
+type monitor struct {
+	app      string
+	minReady int
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	// violation describes the first time the application was below
+	// minReady. It must only be read after stop returns.
+	violation string
+}
+
+func startMonitor(ctx context.Context, c klient.Client, namespace, app string, minReady int) *monitor {
+	// The monitor outlives the step that starts it, so it must not be
+	// cancelled along with the step.
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	m := &monitor{app: app, minReady: minReady, cancel: cancel}
+	sel := &metav1.LabelSelector{MatchLabels: selector(app)}
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+
+		ticker := time.NewTicker(framework.PollInterval())
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			// The API server may be briefly unavailable while the control
+			// plane is upgraded, which says nothing about the application.
+			pods, err := pod.List(ctx, c, namespace, sel)
+			if err != nil {
+				continue
+			}
+
+			ready := 0
+			for i := range pods {
+				if pod.IsReady(&pods[i]) {
+					ready++
+				}
+			}
+			if ready < m.minReady {
+				m.violation = fmt.Sprintf("%d of at least %d pods were ready at %s",
+					ready, m.minReady, time.Now().Format(time.RFC3339))
+				return
+			}
+		}
+	}()
+
+	return m
+}
+
+// stop stops the monitor and waits for it to exit. It may be called more
+// than once.
+func (m *monitor) stop() {
+	m.cancel()
+	m.wg.Wait()
+}
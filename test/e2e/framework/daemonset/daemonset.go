@@ -0,0 +1,103 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package daemonset contains helpers for inspecting DaemonSets in tests.
+package daemonset
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/e2e-framework/klient"
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/klient/wait/conditions"
+
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/pod"
+)
+
+// IsAvailable reports whether every scheduled pod of the DaemonSet is updated
+// and available.
+func IsAvailable(ds *appsv1.DaemonSet) bool {
+	return ds.Status.ObservedGeneration >= ds.Generation &&
+		ds.Status.UpdatedNumberScheduled == ds.Status.DesiredNumberScheduled &&
+		ds.Status.NumberAvailable == ds.Status.DesiredNumberScheduled
+}
+
+// WaitForAvailable waits for every scheduled pod of the DaemonSet to be
+// updated and available within the configured pod start timeout.
+func WaitForAvailable(ctx context.Context, c klient.Client, ds *appsv1.DaemonSet) error {
+	return wait.For(conditions.New(c.Resources()).ResourceMatch(ds, func(obj k8s.Object) bool {
+		return IsAvailable(obj.(*appsv1.DaemonSet))
+	}),
+		wait.WithContext(ctx),
+		wait.WithTimeout(framework.NewTimeoutContext().PodStart),
+		wait.WithInterval(framework.PollInterval()),
+		wait.WithImmediate())
+}
+
+// AssertReadyOnAllNodes waits for all scheduled pods of the DaemonSet to be
+// available, then checks that every node in the cluster runs a ready pod of
+// the DaemonSet and that none of its containers have restarted more than the
+// configured threshold.
+func AssertReadyOnAllNodes(ctx context.Context, t *testing.T, c klient.Client, namespace, name string) {
+	t.Helper()
+
+	var ds appsv1.DaemonSet
+	if err := c.Resources().Get(ctx, name, namespace, &ds); err != nil {
+		t.Fatalf("unable to get daemonset %s/%s: %s", namespace, name, err)
+	}
+
+	err := wait.For(conditions.New(c.Resources()).ResourceMatch(&ds, func(obj k8s.Object) bool {
+		ds := obj.(*appsv1.DaemonSet)
+		return ds.Status.DesiredNumberScheduled > 0 && IsAvailable(ds)
+	}),
+		wait.WithContext(ctx),
+		wait.WithTimeout(framework.NewTimeoutContext().PodStart),
+		wait.WithInterval(framework.PollInterval()),
+		wait.WithImmediate())
+	if err != nil {
+		t.Errorf("daemonset %s/%s has %d of %d pods available: %s",
+			namespace, name, ds.Status.NumberAvailable, ds.Status.DesiredNumberScheduled, err)
+	}
+
+	pods, err := pod.List(ctx, c, namespace, ds.Spec.Selector)
+	if err != nil {
+		t.Fatalf("unable to list pods of daemonset %s/%s: %s", namespace, name, err)
+	}
+
+	var nodes corev1.NodeList
+	if err := c.Resources().List(ctx, &nodes); err != nil {
+		t.Fatalf("unable to list nodes: %s", err)
+	}
+
+	ready := make(map[string]bool, len(nodes.Items))
+	for i := range pods {
+		if pod.IsReady(&pods[i]) {
+			ready[pods[i].Spec.NodeName] = true
+		}
+	}
+	for _, n := range nodes.Items {
+		if !ready[n.Name] {
+			t.Errorf("node %s has no ready pod of daemonset %s/%s", n.Name, namespace, name)
+		}
+	}
+
+	pod.AssertRestartsWithin(t, pods, framework.TestContext.MaxContainerRestarts)
+}
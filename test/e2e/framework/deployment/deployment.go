@@ -0,0 +1,79 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package deployment contains helpers for inspecting Deployments in tests.
+package deployment
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"sigs.k8s.io/e2e-framework/klient"
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/klient/wait/conditions"
+
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/pod"
+)
+
+// IsAvailable reports whether all replicas of the Deployment are updated and
+// available.
+func IsAvailable(d *appsv1.Deployment) bool {
+	desired := int32(1)
+	if d.Spec.Replicas != nil {
+		desired = *d.Spec.Replicas
+	}
+	return d.Status.ObservedGeneration >= d.Generation &&
+		d.Status.UpdatedReplicas == desired &&
+		d.Status.AvailableReplicas == desired
+}
+
+// WaitForAvailable waits for all replicas of the Deployment to be updated and
+// available within the configured pod start timeout.
+func WaitForAvailable(ctx context.Context, c klient.Client, d *appsv1.Deployment) error {
+	return wait.For(conditions.New(c.Resources()).ResourceMatch(d, func(obj k8s.Object) bool {
+		return IsAvailable(obj.(*appsv1.Deployment))
+	}),
+		wait.WithContext(ctx),
+		wait.WithTimeout(framework.NewTimeoutContext().PodStart),
+		wait.WithInterval(framework.PollInterval()),
+		wait.WithImmediate())
+}
+
+// AssertAvailable waits for all replicas of the Deployment to be available
+// and checks that none of its containers have restarted more than the
+// configured threshold.
+func AssertAvailable(ctx context.Context, t *testing.T, c klient.Client, namespace, name string) {
+	t.Helper()
+
+	var d appsv1.Deployment
+	if err := c.Resources().Get(ctx, name, namespace, &d); err != nil {
+		t.Fatalf("unable to get deployment %s/%s: %s", namespace, name, err)
+	}
+
+	if err := WaitForAvailable(ctx, c, &d); err != nil {
+		t.Errorf("deployment %s/%s has %d of %d replicas available: %s",
+			namespace, name, d.Status.AvailableReplicas, d.Status.Replicas, err)
+	}
+
+	pods, err := pod.List(ctx, c, namespace, d.Spec.Selector)
+	if err != nil {
+		t.Fatalf("unable to list pods of deployment %s/%s: %s", namespace, name, err)
+	}
+	pod.AssertRestartsWithin(t, pods, framework.TestContext.MaxContainerRestarts)
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package events captures the Warning events emitted while each feature
+// runs. Many problems, such as an addon that cannot be scheduled or a volume
+// that cannot be mounted, only surface as events.
+package events
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+)
+
+// recorder collects the Warning events of a feature from a watch on each
+// namespace.
+type recorder struct {
+	watchers []*watchtools.RetryWatcher
+	wg       sync.WaitGroup
+
+	mu sync.Mutex
+	// events holds the latest version of each event, as repeated events are
+	// updated in place with an incremented count.
+	events map[types.UID]corev1.Event
+}
+
+// Record begins watching for Warning events in the namespaces configured with
+// -event-namespaces. Once the feature finishes, every Warning event captured
+// while it ran is logged to it, and it fails if any of them have a reason
+// configured with -fail-on-event-reasons. Only events emitted or repeated
+// after Record are captured. It is meant to be registered with
+// [framework.TestContextType.SetupEachFeature] so that it runs with the
+// feature's own test.
+//
+// Features run in parallel share namespaces, so events may be attributed to
+// every feature that was running when they occurred.
+func Record(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+	r, err := start(ctx, c)
+	if err != nil {
+		t.Errorf("unable to capture Warning events: %s", err)
+		return ctx
+	}
+
+	// Cleanup runs once the feature finishes, even if its Setup fails and
+	// its Teardown is skipped.
+	t.Cleanup(func() {
+		r.stop()
+		r.report(t)
+	})
+	return ctx
+}
+
+func start(ctx context.Context, c *envconf.Config) (*recorder, error) {
+	clientset, err := kubernetes.NewForConfig(c.Client().RESTConfig())
+	if err != nil {
+		return nil, fmt.Errorf("creating clientset for events: %w", err)
+	}
+
+	selector := fields.OneTermEqualSelector("type", corev1.EventTypeWarning).String()
+	r := &recorder{events: map[types.UID]corev1.Event{}}
+
+	for _, ns := range namespaces(c) {
+		events := clientset.CoreV1().Events(ns)
+
+		// Listing establishes the resource version to watch from, so that
+		// events which occurred before the feature are not captured.
+		list, err := events.List(ctx, metav1.ListOptions{FieldSelector: selector, Limit: 1})
+		if err != nil {
+			r.stop()
+			return nil, fmt.Errorf("listing events in namespace %q: %w", ns, err)
+		}
+
+		// The retry watcher resumes the watch if the API server restarts,
+		// such as during a control plane upgrade.
+		w, err := watchtools.NewRetryWatcher(list.ResourceVersion, &cache.ListWatch{
+			WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+				opts.FieldSelector = selector
+				return events.Watch(context.WithoutCancel(ctx), opts)
+			},
+		})
+		if err != nil {
+			r.stop()
+			return nil, fmt.Errorf("watching events in namespace %q: %w", ns, err)
+		}
+		r.watchers = append(r.watchers, w)
+
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			r.record(w)
+		}()
+	}
+
+	return r, nil
+}
+
+// report logs every captured event to the feature, failing it for those with
+// a reason configured with -fail-on-event-reasons.
+func (r *recorder) report(t *testing.T) {
+	events := r.list()
+	for i := range events {
+		e := &events[i]
+		msg := fmt.Sprintf("%s %s %s/%s in namespace %q: %s (x%d)",
+			e.Type, e.Reason, e.InvolvedObject.Kind, e.InvolvedObject.Name, e.Namespace, e.Message, count(e))
+
+		if slices.Contains(framework.TestContext.FailOnEventReasons, e.Reason) {
+			t.Error(msg)
+		} else {
+			t.Log(msg)
+		}
+	}
+}
+
+// namespaces returns the namespaces of the cluster under test to watch for
+// events.
+func namespaces(c *envconf.Config) []string {
+	if len(framework.TestContext.EventNamespaces) > 0 {
+		return framework.TestContext.EventNamespaces
+	}
+
+	ns := []string{metav1.NamespaceSystem}
+	if n := c.Namespace(); n != "" && n != metav1.NamespaceSystem {
+		ns = append(ns, n)
+	}
+	return ns
+}
+
+func (r *recorder) record(w watch.Interface) {
+	for ev := range w.ResultChan() {
+		e, ok := ev.Object.(*corev1.Event)
+		if !ok || (ev.Type != watch.Added && ev.Type != watch.Modified) {
+			continue
+		}
+
+		r.mu.Lock()
+		r.events[e.UID] = *e
+		r.mu.Unlock()
+	}
+}
+
+func (r *recorder) stop() {
+	for _, w := range r.watchers {
+		w.Stop()
+	}
+	r.wg.Wait()
+}
+
+// list returns the captured events in the order they were last seen.
+func (r *recorder) list() []corev1.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := make([]corev1.Event, 0, len(r.events))
+	for _, e := range r.events {
+		events = append(events, e)
+	}
+	sort.Slice(events, func(i, j int) bool { return lastSeen(&events[i]).Before(lastSeen(&events[j])) })
+	return events
+}
+
+// lastSeen returns the last time the event occurred, accounting for both
+// the core and events.k8s.io APIs populating different fields.
+func lastSeen(e *corev1.Event) time.Time {
+	switch {
+	case e.Series != nil:
+		return e.Series.LastObservedTime.Time
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp.Time
+	default:
+		return e.EventTime.Time
+	}
+}
+
+// count returns how many times the event occurred.
+func count(e *corev1.Event) int32 {
+	switch {
+	case e.Series != nil:
+		return e.Series.Count
+	case e.Count > 0:
+		return e.Count
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kinds
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Condition is the subset of a status condition inspected by tests. Cluster
+// API and the Kubernetes Service share this shape across their kinds.
+type Condition struct {
+	Type    string
+	Status  string
+	Reason  string
+	Message string
+}
+
+// IsTrue reports whether the condition status is "True".
+func (c Condition) IsTrue() bool {
+	return c.Status == "True"
+}
+
+// Conditions returns the status conditions of an unstructured object.
+func Conditions(u *unstructured.Unstructured) []Condition {
+	raw, _, _ := unstructured.NestedSlice(u.Object, "status", "conditions")
+
+	conds := make([]Condition, 0, len(raw))
+	for _, r := range raw {
+		m, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		c := Condition{}
+		c.Type, _, _ = unstructured.NestedString(m, "type")
+		c.Status, _, _ = unstructured.NestedString(m, "status")
+		c.Reason, _, _ = unstructured.NestedString(m, "reason")
+		c.Message, _, _ = unstructured.NestedString(m, "message")
+		conds = append(conds, c)
+	}
+	return conds
+}
+
+// GetCondition returns the condition of the given type and whether it was
+// found.
+func GetCondition(u *unstructured.Unstructured, condType string) (Condition, bool) {
+	for _, c := range Conditions(u) {
+		if c.Type == condType {
+			return c, true
+		}
+	}
+	return Condition{}, false
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kinds describes the resource kinds owned by the Kubernetes Service
+// and Cluster API that are not part of the core Kubernetes scheme.
+//
+// Resources are accessed as unstructured objects so the suite does not depend
+// on the Go types of every provider it exercises.
+package kinds
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ClusterNameLabel is set by Cluster API on every object belonging to a
+// Cluster.
+const ClusterNameLabel = "cluster.x-k8s.io/cluster-name"
+
+// TanzuKubernetesReleaseLabel is set on a Cluster, and the Machines created
+// for it, to the name of the TanzuKubernetesRelease it was resolved to.
+const TanzuKubernetesReleaseLabel = "run.tanzu.vmware.com/tkr"
+
+var (
+	// Cluster is the Cluster API Cluster kind.
+	Cluster = schema.GroupVersionKind{Group: "cluster.x-k8s.io", Version: "v1beta1", Kind: "Cluster"}
+
+	// Machine is the Cluster API Machine kind.
+	Machine = schema.GroupVersionKind{Group: "cluster.x-k8s.io", Version: "v1beta1", Kind: "Machine"}
+
+	// MachineSet is the Cluster API MachineSet kind.
+	MachineSet = schema.GroupVersionKind{Group: "cluster.x-k8s.io", Version: "v1beta1", Kind: "MachineSet"}
+
+	// MachineDeployment is the Cluster API MachineDeployment kind.
+	MachineDeployment = schema.GroupVersionKind{Group: "cluster.x-k8s.io", Version: "v1beta1", Kind: "MachineDeployment"}
+
+	// KubeadmControlPlane is the Cluster API kubeadm control plane kind.
+	KubeadmControlPlane = schema.GroupVersionKind{Group: "controlplane.cluster.x-k8s.io", Version: "v1beta1", Kind: "KubeadmControlPlane"}
+
+	// TanzuKubernetesCluster is the Kubernetes Service's own cluster kind.
+	TanzuKubernetesCluster = schema.GroupVersionKind{Group: "run.tanzu.vmware.com", Version: "v1alpha3", Kind: "TanzuKubernetesCluster"}
+
+	// TanzuKubernetesRelease describes a Kubernetes version available to
+	// clusters on the Kubernetes Service.
+	TanzuKubernetesRelease = schema.GroupVersionKind{Group: "run.tanzu.vmware.com", Version: "v1alpha3", Kind: "TanzuKubernetesRelease"}
+
+	// OSImage describes a node image that a TanzuKubernetesRelease can be
+	// deployed with.
+	OSImage = schema.GroupVersionKind{Group: "run.tanzu.vmware.com", Version: "v1alpha3", Kind: "OSImage"}
+
+	// ClusterBootstrap describes the addons installed into a cluster.
+	ClusterBootstrap = schema.GroupVersionKind{Group: "run.tanzu.vmware.com", Version: "v1alpha3", Kind: "ClusterBootstrap"}
+
+	// AntreaControllerInfo reports the health of the Antrea controller.
+	AntreaControllerInfo = schema.GroupVersionKind{Group: "crd.antrea.io", Version: "v1beta1", Kind: "AntreaControllerInfo"}
+
+	// ProviderServiceAccount requests a Kubernetes Service ServiceAccount
+	// whose token is made available inside a workload cluster.
+	ProviderServiceAccount = schema.GroupVersionKind{Group: "vmware.infrastructure.cluster.x-k8s.io", Version: "v1beta1", Kind: "ProviderServiceAccount"}
+
+	// VirtualMachineService exposes workload cluster Services through a load
+	// balancer on the Kubernetes Service.
+	VirtualMachineService = schema.GroupVersionKind{Group: "vmoperator.vmware.com", Version: "v1alpha1", Kind: "VirtualMachineService"}
+
+	// CalicoIPPool is a range of addresses Calico allocates pod IPs from.
+	CalicoIPPool = schema.GroupVersionKind{Group: "crd.projectcalico.org", Version: "v1", Kind: "IPPool"}
+
+	// CalicoBlockAffinity assigns a block of a Calico IPPool to a node.
+	CalicoBlockAffinity = schema.GroupVersionKind{Group: "crd.projectcalico.org", Version: "v1", Kind: "BlockAffinity"}
+
+	// AntreaAgentInfo reports the health of the Antrea agent on a node.
+	AntreaAgentInfo = schema.GroupVersionKind{Group: "crd.antrea.io", Version: "v1beta1", Kind: "AntreaAgentInfo"}
+
+	// APIService registers an aggregated API with the Kubernetes API server.
+	APIService = schema.GroupVersionKind{Group: "apiregistration.k8s.io", Version: "v1", Kind: "APIService"}
+
+	// NodeMetrics is the resource usage of a node served by metrics-server.
+	NodeMetrics = schema.GroupVersionKind{Group: "metrics.k8s.io", Version: "v1beta1", Kind: "NodeMetrics"}
+
+	// PodMetrics is the resource usage of a pod served by metrics-server.
+	PodMetrics = schema.GroupVersionKind{Group: "metrics.k8s.io", Version: "v1beta1", Kind: "PodMetrics"}
+)
+
+// New returns an empty unstructured object of the given kind.
+func New(gvk schema.GroupVersionKind) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(gvk)
+	return u
+}
+
+// NewList returns an empty unstructured list for items of the given kind.
+func NewList(gvk schema.GroupVersionKind) *unstructured.UnstructuredList {
+	l := &unstructured.UnstructuredList{}
+	l.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	return l
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	"sigs.k8s.io/e2e-framework/pkg/types"
+
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/testlabels"
+)
+
+// Modes that the suite can be run in with -mode.
+const (
+	// ModeDefault runs the functional suites.
+	ModeDefault = "default"
+
+	// ModeChaos exclusively runs tests labeled with [testlabels.Chaos].
+	ModeChaos = "chaos"
+
+	// ModeUpgrade exclusively runs tests labeled with [testlabels.Upgrade],
+	// upgrading the Kubernetes Distribution from -upgrade-from to
+	// -upgrade-to.
+	ModeUpgrade = "upgrade"
+)
+
+var modes = []string{ModeDefault, ModeChaos, ModeUpgrade}
+
+// exclusiveLabels are the labels of tests that may only be run in a mode,
+// keyed by that mode. Only tests with the label may be run in the mode.
+var exclusiveLabels = map[string]func() (string, string){
+	ModeChaos:   testlabels.Chaos,
+	ModeUpgrade: testlabels.Upgrade,
+}
+
+func validateMode(mode string) error {
+	if !slices.Contains(modes, mode) {
+		return fmt.Errorf("unknown mode %q, valid modes are %s", mode, strings.Join(modes, ", "))
+	}
+	return nil
+}
+
+// validateUpgrade ensures that the versions to upgrade between are valid and
+// that the cluster under test can be upgraded on the Kubernetes Service.
+func validateUpgrade(tc *TestContextType) error {
+	if tc.UpgradeFrom == "" || tc.UpgradeTo == "" {
+		return errors.New("-upgrade-from and -upgrade-to are required")
+	}
+	if tc.ClusterName == "" || tc.ClusterNamespace == "" || tc.SupervisorKubeconfig == "" {
+		return errors.New("-cluster-name, -cluster-namespace and -supervisor-kubeconfig are required")
+	}
+
+	from, err := utilversion.ParseSemantic(tc.UpgradeFrom)
+	if err != nil {
+		return fmt.Errorf("-upgrade-from: %w", err)
+	}
+	to, err := utilversion.ParseSemantic(tc.UpgradeTo)
+	if err != nil {
+		return fmt.Errorf("-upgrade-to: %w", err)
+	}
+	if !from.LessThan(to) {
+		return fmt.Errorf("-upgrade-to %s must be newer than -upgrade-from %s", tc.UpgradeTo, tc.UpgradeFrom)
+	}
+	return nil
+}
+
+// validateFeatureMode records a bug if the feature may not be run in the
+// mode of the test context. Chaos and upgrade tests may only run in their own
+// modes, and only those tests may run in them, so they are never mixed with
+// other tests.
+func validateFeatureMode(tc *TestContextType, f types.Feature) {
+	for mode, label := range exclusiveLabels {
+		_, kind := label()
+		labeled := f.Labels().Contains(label())
+
+		switch {
+		case tc.Mode == mode && !labeled:
+			RecordBug(NewBug(fmt.Sprintf("only %s tests may be run in %s mode: %q", strings.ToLower(kind), mode, f.Name()), 2))
+		case tc.Mode != mode && labeled:
+			RecordBug(NewBug(fmt.Sprintf("%s tests may only be run in %s mode: %q", strings.ToLower(kind), mode, f.Name()), 2))
+		}
+	}
+}
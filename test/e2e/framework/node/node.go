@@ -0,0 +1,75 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package node contains helpers for inspecting Nodes in tests.
+package node
+
+import (
+	"context"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/e2e-framework/klient"
+)
+
+// List returns all nodes in the cluster sorted by name.
+func List(ctx context.Context, c klient.Client) ([]corev1.Node, error) {
+	var nodes corev1.NodeList
+	if err := c.Resources().List(ctx, &nodes); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(nodes.Items, func(i, j int) bool { return nodes.Items[i].Name < nodes.Items[j].Name })
+	return nodes.Items, nil
+}
+
+// ListSchedulable returns the nodes that are ready and not cordoned, sorted
+// by name.
+func ListSchedulable(ctx context.Context, c klient.Client) ([]corev1.Node, error) {
+	nodes, err := List(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	schedulable := make([]corev1.Node, 0, len(nodes))
+	for _, n := range nodes {
+		if !n.Spec.Unschedulable && IsReady(&n) {
+			schedulable = append(schedulable, n)
+		}
+	}
+	return schedulable, nil
+}
+
+// IsReady reports whether the node has a true Ready condition.
+func IsReady(n *corev1.Node) bool {
+	for _, cond := range n.Status.Conditions {
+		if cond.Type == corev1.NodeReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// Address returns the first address of the given type reported by the node,
+// or an empty string if there is none.
+func Address(n *corev1.Node, addrType corev1.NodeAddressType) string {
+	for _, a := range n.Status.Addresses {
+		if a.Type == addrType {
+			return a.Address
+		}
+	}
+	return ""
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package pod contains helpers for inspecting Pods in tests.
+package pod
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/e2e-framework/klient"
+	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/klient/wait/conditions"
+
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+)
+
+// List returns the Pods in the namespace matched by the label selector.
+func List(ctx context.Context, c klient.Client, namespace string, selector *metav1.LabelSelector) ([]corev1.Pod, error) {
+	sel, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid label selector: %w", err)
+	}
+
+	var pods corev1.PodList
+	if err := c.Resources(namespace).List(ctx, &pods, resources.WithLabelSelector(sel.String())); err != nil {
+		return nil, err
+	}
+	return pods.Items, nil
+}
+
+// IsReady reports whether the Pod is running with a true Ready condition.
+func IsReady(p *corev1.Pod) bool {
+	if p.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	for _, cond := range p.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// WaitForReady waits for each of the Pods to become ready within the
+// configured pod start timeout.
+func WaitForReady(ctx context.Context, c klient.Client, pods ...*corev1.Pod) error {
+	for _, p := range pods {
+		err := wait.For(conditions.New(c.Resources()).PodReady(p),
+			wait.WithContext(ctx),
+			wait.WithTimeout(framework.NewTimeoutContext().PodStart),
+			wait.WithInterval(framework.PollInterval()))
+		if err != nil {
+			return fmt.Errorf("waiting for pod %s/%s to be ready: %w", p.Namespace, p.Name, err)
+		}
+	}
+	return nil
+}
+
+// AssertRestartsWithin reports every container of the Pods that has
+// restarted more than max times.
+func AssertRestartsWithin(t *testing.T, pods []corev1.Pod, max int) {
+	t.Helper()
+
+	for _, p := range pods {
+		statuses := append([]corev1.ContainerStatus{}, p.Status.InitContainerStatuses...)
+		statuses = append(statuses, p.Status.ContainerStatuses...)
+		for _, s := range statuses {
+			if int(s.RestartCount) > max {
+				t.Errorf("container %s of pod %s/%s on node %s restarted %d times, more than %d",
+					s.Name, p.Namespace, p.Name, p.Spec.NodeName, s.RestartCount, max)
+			}
+		}
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package probe creates pods that serve and test network connectivity from
+// inside a cluster.
+package probe
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/e2e-framework/klient"
+)
+
+const (
+	// Image is the image run by probe pods.
+	Image = "registry.k8s.io/e2e-test-images/agnhost:2.47"
+
+	// Port is the port on which probe pods serve HTTP.
+	Port = 8080
+
+	// container is the name of the probe container.
+	container = "probe"
+)
+
+// NewDeployment returns a Deployment of probe pods serving HTTP on Port. The
+// labels are used both to select the pods and to label them.
+func NewDeployment(name, namespace string, replicas int32, labels map[string]string) *appsv1.Deployment {
+	template := NewPod("", "", "", labels)
+
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: template.ObjectMeta,
+				Spec:       template.Spec,
+			},
+		},
+	}
+}
+
+// NewPod returns a probe pod serving HTTP on Port. If node is not empty the
+// pod is bound to that node and tolerates all of its taints.
+func NewPod(name, namespace, node string, labels map[string]string) *corev1.Pod {
+	p := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  container,
+				Image: Image,
+				Args:  []string{"netexec", fmt.Sprintf("--http-port=%d", Port)},
+				Ports: []corev1.ContainerPort{{ContainerPort: Port}},
+				ReadinessProbe: &corev1.Probe{
+					ProbeHandler: corev1.ProbeHandler{
+						TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt32(Port)},
+					},
+					PeriodSeconds: 2,
+				},
+			}},
+		},
+	}
+
+	if node != "" {
+		p.Spec.NodeName = node
+		p.Spec.Tolerations = []corev1.Toleration{{Operator: corev1.TolerationOpExists}}
+	}
+
+	return p
+}
+
+// Connect checks from within the probe pod p that a TCP connection can be
+// established to host:port.
+func Connect(ctx context.Context, c klient.Client, p *corev1.Pod, host string, port int32) error {
+	var stdout, stderr bytes.Buffer
+	addr := net.JoinHostPort(host, strconv.Itoa(int(port)))
+	cmd := []string{"/agnhost", "connect", "--timeout=5s", addr}
+
+	if err := c.Resources().ExecInPod(ctx, p.Namespace, p.Name, container, cmd, &stdout, &stderr); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s: %s", msg, err)
+		}
+		return err
+	}
+	return nil
+}
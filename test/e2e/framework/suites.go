@@ -0,0 +1,95 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Suites of the Kubernetes Service that can be selected with -service-suites.
+const (
+	// SuiteCluster exercises the lifecycle of a Cluster API Cluster.
+	SuiteCluster = "cluster"
+
+	// SuiteTanzuKubernetesCluster exercises the lifecycle of a
+	// TanzuKubernetesCluster.
+	SuiteTanzuKubernetesCluster = "tanzukubernetescluster"
+
+	// SuiteTanzuKubernetesRelease verifies the TanzuKubernetesReleases
+	// available to clusters.
+	SuiteTanzuKubernetesRelease = "tanzukubernetesrelease"
+)
+
+var serviceSuites = []string{SuiteCluster, SuiteTanzuKubernetesCluster, SuiteTanzuKubernetesRelease}
+
+// RunsServiceSuite reports whether the named Kubernetes Service suite was
+// selected to run.
+func (tc *TestContextType) RunsServiceSuite(name string) bool {
+	return slices.Contains(tc.ServiceSuites, name)
+}
+
+// parseServiceSuites parses a comma-separated list of Kubernetes Service
+// suites.
+func parseServiceSuites(value string) ([]string, error) {
+	var suites []string
+	for _, s := range strings.Split(value, ",") {
+		s = strings.TrimSpace(s)
+		switch {
+		case s == "":
+			continue
+		case !slices.Contains(serviceSuites, s):
+			return nil, fmt.Errorf("unknown suite %q, valid suites are %s", s, strings.Join(serviceSuites, ", "))
+		case !slices.Contains(suites, s):
+			suites = append(suites, s)
+		}
+	}
+
+	return suites, nil
+}
+
+// validateServiceSuites checks that each selected lifecycle suite has a
+// cluster of its own. Both suites finish by deleting their cluster, so when
+// they are run together the TanzuKubernetesCluster suite must target a
+// different one.
+func validateServiceSuites(tc *TestContextType) error {
+	if !tc.RunsServiceSuite(SuiteCluster) || !tc.RunsServiceSuite(SuiteTanzuKubernetesCluster) {
+		return nil
+	}
+
+	tkc := tc.ForTanzuKubernetesCluster()
+	if tkc.ClusterName == tc.ClusterName && tkc.ClusterNamespace == tc.ClusterNamespace {
+		return fmt.Errorf("suites %s and %s both delete their cluster, -tkc-name or -tkc-namespace must select a different cluster than -cluster-name and -cluster-namespace",
+			SuiteCluster, SuiteTanzuKubernetesCluster)
+	}
+	return nil
+}
+
+// ForTanzuKubernetesCluster returns a copy of the context targeting the
+// TanzuKubernetesCluster selected with -tkc-name and -tkc-namespace, which
+// default to -cluster-name and -cluster-namespace.
+func (tc *TestContextType) ForTanzuKubernetesCluster() *TestContextType {
+	tkc := *tc
+	if tc.TKCName != "" {
+		tkc.ClusterName = tc.TKCName
+	}
+	if tc.TKCNamespace != "" {
+		tkc.ClusterNamespace = tc.TKCNamespace
+	}
+	return &tkc
+}
@@ -22,7 +22,9 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -50,6 +52,93 @@ type TestContextType struct {
 	// ShuffleSeed is the seed used when setting up the RNG used for shuffling.
 	ShuffleSeed int64
 
+	// Mode determines which kinds of tests are run. Tests of different modes
+	// are never mixed within a run.
+	Mode string
+
+	// UpgradeFrom is the Kubernetes version the cluster under test is
+	// expected to run before it is upgraded in upgrade mode.
+	UpgradeFrom string
+
+	// UpgradeTo is the Kubernetes version the cluster under test is upgraded
+	// to in upgrade mode. It is set as the version of the Cluster topology.
+	UpgradeTo string
+
+	// MaxUpgradeDowntime is the longest the API server of the cluster under
+	// test may be unavailable at a time while it is upgraded.
+	MaxUpgradeDowntime time.Duration
+
+	// serviceSuitesFlag contains the contents of the command line flag that
+	// is used to set ServiceSuites
+	serviceSuitesFlag string
+
+	// ServiceSuites are the Kubernetes Service suites selected to run. The
+	// selected suites are composed into a single test run.
+	ServiceSuites []string
+
+	// ClusterName is the name of the Cluster or TanzuKubernetesCluster under
+	// test on the Kubernetes Service.
+	ClusterName string
+
+	// ClusterNamespace is the namespace of the Cluster or
+	// TanzuKubernetesCluster under test on the Kubernetes Service.
+	ClusterNamespace string
+
+	// TKCName is the name of the TanzuKubernetesCluster under test by the
+	// TanzuKubernetesCluster suite. If empty, ClusterName is used.
+	TKCName string
+
+	// TKCNamespace is the namespace of the TanzuKubernetesCluster under test
+	// by the TanzuKubernetesCluster suite. If empty, ClusterNamespace is used.
+	TKCNamespace string
+
+	// SupervisorKubeconfig is the path to a kubeconfig for the Kubernetes
+	// Service managing the workload cluster under test. It is only required
+	// by workload cluster features that verify state on the Kubernetes
+	// Service.
+	SupervisorKubeconfig string
+
+	// MaxContainerRestarts is the number of times a container of a system
+	// component may restart before it is reported as unhealthy.
+	MaxContainerRestarts int
+
+	// AntreaVersion is the version of Antrea expected to be installed in the
+	// workload cluster. If empty, any version is accepted as long as all
+	// Antrea components agree.
+	AntreaVersion string
+
+	// ClusterDomain is the DNS domain of Services in the workload cluster.
+	ClusterDomain string
+
+	// MaxDNSLatency is the longest a DNS query in the workload cluster may
+	// take before it is reported as slow.
+	MaxDNSLatency time.Duration
+
+	// ExternalDNSName is a name outside of the cluster that must resolve
+	// through the cluster DNS. If empty, external resolution is not checked.
+	ExternalDNSName string
+
+	// CertificateExpiryWindow is how long certificates of the cluster under
+	// test must remain valid for before they are reported as expiring.
+	CertificateExpiryWindow time.Duration
+
+	// eventNamespacesFlag contains the contents of the command line flag
+	// that is used to set EventNamespaces
+	eventNamespacesFlag string
+
+	// EventNamespaces are the namespaces watched for Warning events while
+	// each feature runs. If empty, kube-system and the test namespace are
+	// watched.
+	EventNamespaces []string
+
+	// failOnEventReasonsFlag contains the contents of the command line flag
+	// that is used to set FailOnEventReasons
+	failOnEventReasonsFlag string
+
+	// FailOnEventReasons are the reasons of Warning events that fail the
+	// feature during which they occur, such as FailedScheduling or BackOff.
+	FailOnEventReasons []string
+
 	// timeouts contains user-configurable timeouts for various operations.
 	// Individual Framework instance also have such timeouts which may be
 	// different from these here. To avoid confusion, this field is not
@@ -57,10 +146,22 @@ type TestContextType struct {
 	// NewTimeoutContext.
 	timeouts TimeoutContext
 
+	// featureSetups are run at the start of the Setup of every feature run
+	// by a test runner.
+	featureSetups []types.StepFunc
+
 	// versionFlag displays version information then exits.
 	versionFlag bool
 }
 
+// SetupEachFeature registers a step that is run at the start of the Setup of
+// every feature run by a test runner. Unlike the hooks of
+// [env.Environment.BeforeEachFeature], the step is run with the feature's own
+// test, so that anything it reports is attributed to the feature.
+func (tc *TestContextType) SetupEachFeature(fn types.StepFunc) {
+	tc.featureSetups = append(tc.featureSetups, fn)
+}
+
 // Test is a wrapper around [TestEnv.Test] that ensures features are being
 // run in accordance to label semantics.
 // For example, it will ensure that disruptive tests are not run alongside
@@ -68,7 +169,7 @@ type TestContextType struct {
 func (tc *TestContextType) Test(t *testing.T, testFeatures ...types.Feature) context.Context {
 	// TODO(tvs): Validate testFeatures are being run in accordance to label
 	// semantics
-	return tc.TestEnv.Test(t, testFeatures...)
+	return tc.TestEnv.Test(t, tc.withFeatureSetups(testFeatures)...)
 }
 
 // TestInParallel is a wrapper around [TestEnv.TestInParallel] that ensures
@@ -88,12 +189,18 @@ func (tc *TestContextType) TestInParallel(t *testing.T, testFeatures ...types.Fe
 
 	// TODO(tvs): Validate testFeatures are being run in accordance to label
 	// semantics
-	return tc.TestEnv.TestInParallel(t, testFeatures...)
+	return tc.TestEnv.TestInParallel(t, tc.withFeatureSetups(testFeatures)...)
 }
 
 // TestContext should be used by all tests to access common context data.
 var TestContext = TestContextType{
-	timeouts: defaultTimeouts,
+	timeouts:                defaultTimeouts,
+	MaxContainerRestarts:    3,
+	ClusterDomain:           "cluster.local",
+	MaxDNSLatency:           500 * time.Millisecond,
+	ExternalDNSName:         "kubernetes.io",
+	MaxUpgradeDowntime:      time.Minute,
+	CertificateExpiryWindow: 30 * 24 * time.Hour,
 }
 
 // RegisterCommonFlags registers flags common to all e2e test suites.
@@ -110,6 +217,24 @@ var TestContext = TestContextType{
 func RegisterCommonFlags(flags *flag.FlagSet, tc *TestContextType) {
 	flags.BoolVar(&tc.versionFlag, "version", false, "Displays version information")
 	flags.StringVar(&tc.shuffleFlag, "shuffle", "off", "Shuffle tests within testing sequences. Valid values are 'off', 'on', or a valid integer that will be used as the RNG seed.")
+	flags.StringVar(&tc.Mode, "mode", ModeDefault, fmt.Sprintf("Mode to run the suite in. Valid modes are %s.", strings.Join(modes, ", ")))
+	flags.StringVar(&tc.UpgradeFrom, "upgrade-from", "", fmt.Sprintf("Kubernetes version the cluster under test runs before it is upgraded. Required in %s mode.", ModeUpgrade))
+	flags.StringVar(&tc.UpgradeTo, "upgrade-to", "", fmt.Sprintf("Kubernetes version the cluster under test is upgraded to. Required in %s mode.", ModeUpgrade))
+	flags.DurationVar(&tc.MaxUpgradeDowntime, "max-upgrade-downtime", TestContext.MaxUpgradeDowntime, "Longest the API server of the cluster under test may be unavailable at a time while it is upgraded.")
+	flags.StringVar(&tc.serviceSuitesFlag, "service-suites", "", fmt.Sprintf("Comma-separated list of Kubernetes Service suites to run. Valid suites are %s.", strings.Join(serviceSuites, ", ")))
+	flags.StringVar(&tc.ClusterName, "cluster-name", "", "Name of the Cluster or TanzuKubernetesCluster under test.")
+	flags.StringVar(&tc.ClusterNamespace, "cluster-namespace", "", "Namespace of the Cluster or TanzuKubernetesCluster under test.")
+	flags.StringVar(&tc.TKCName, "tkc-name", "", fmt.Sprintf("Name of the TanzuKubernetesCluster under test by the %s suite. Defaults to -cluster-name.", SuiteTanzuKubernetesCluster))
+	flags.StringVar(&tc.TKCNamespace, "tkc-namespace", "", fmt.Sprintf("Namespace of the TanzuKubernetesCluster under test by the %s suite. Defaults to -cluster-namespace.", SuiteTanzuKubernetesCluster))
+	flags.StringVar(&tc.SupervisorKubeconfig, "supervisor-kubeconfig", "", "Path to a kubeconfig for the Kubernetes Service managing the workload cluster under test.")
+	flags.StringVar(&tc.AntreaVersion, "antrea-version", "", "Version of Antrea expected to be installed in the workload cluster.")
+	flags.IntVar(&tc.MaxContainerRestarts, "max-container-restarts", TestContext.MaxContainerRestarts, "Number of times a container of a system component may restart before it is reported as unhealthy.")
+	flags.StringVar(&tc.ClusterDomain, "cluster-domain", TestContext.ClusterDomain, "DNS domain of Services in the workload cluster.")
+	flags.DurationVar(&tc.CertificateExpiryWindow, "certificate-expiry-window", TestContext.CertificateExpiryWindow, "How long certificates of the cluster under test must remain valid for before they are reported as expiring.")
+	flags.StringVar(&tc.eventNamespacesFlag, "event-namespaces", "", "Comma-separated list of namespaces watched for Warning events while each feature runs. Defaults to kube-system and the test namespace.")
+	flags.StringVar(&tc.failOnEventReasonsFlag, "fail-on-event-reasons", "", "Comma-separated list of Warning event reasons, such as FailedScheduling or BackOff, that fail the feature during which they occur.")
+	flags.DurationVar(&tc.MaxDNSLatency, "max-dns-latency", TestContext.MaxDNSLatency, "Longest a DNS query in the workload cluster may take before it is reported as slow.")
+	flags.StringVar(&tc.ExternalDNSName, "external-dns-name", TestContext.ExternalDNSName, "Name outside of the cluster that must resolve through the workload cluster DNS. Set to empty to skip the check, such as in air-gapped environments.")
 }
 
 // DefaultTestFlags establishes the common default flags that configure a
@@ -143,6 +268,40 @@ func processAndValidateFlags(t *TestContextType) {
 		}
 	}
 	// TODO(tvs): Log shuffle seed
+
+	if err := validateMode(t.Mode); err != nil {
+		log.Fatalf("-mode is invalid: %s", err)
+	}
+	if t.Mode == ModeUpgrade {
+		if err := validateUpgrade(t); err != nil {
+			log.Fatalf("invalid configuration for %s mode: %s", ModeUpgrade, err)
+		}
+	}
+
+	suites, err := parseServiceSuites(t.serviceSuitesFlag)
+	if err != nil {
+		log.Fatalf("-service-suites is invalid: %s", err)
+	}
+	t.ServiceSuites = suites
+	if err := validateServiceSuites(t); err != nil {
+		log.Fatalf("-service-suites is invalid: %s", err)
+	}
+
+	t.EventNamespaces = parseList(t.eventNamespacesFlag)
+	t.FailOnEventReasons = parseList(t.failOnEventReasonsFlag)
+}
+
+// parseList parses a comma-separated list, ignoring empty and duplicate
+// entries.
+func parseList(value string) []string {
+	var list []string
+	for _, v := range strings.Split(value, ",") {
+		v = strings.TrimSpace(v)
+		if v != "" && !slices.Contains(list, v) {
+			list = append(list, v)
+		}
+	}
+	return list
 }
 
 // AfterReadingAllFlags makes changes to the context after all flags
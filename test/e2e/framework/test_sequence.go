@@ -59,11 +59,13 @@ func (s *SerialSequence) Features() []types.Feature {
 
 // Validate ensures that features are being run in accordance to label and
 // configuration semantics.
+// Chaos and upgrade tests may only be run in their own modes.
 // Invalid configuration is recorded as a source code bug and can be retrieved
 // with FormatBugs.
 func (s *SerialSequence) Validate() {
-	// TODO(tvs): Validate testFeatures are being run in accordance to label
-	// semantics
+	for _, f := range s.features {
+		validateFeatureMode(&TestContext, f)
+	}
 }
 
 // Test is a wrapper function around [TestEnv.Test] that offers additional
@@ -75,7 +77,7 @@ func (s *SerialSequence) Test(t *testing.T, tc *TestContextType) context.Context
 		rng.Shuffle(len(s.features), func(i, j int) { s.features[i], s.features[j] = s.features[j], s.features[i] })
 	}
 
-	return tc.TestEnv.Test(t, s.features...)
+	return tc.TestEnv.Test(t, tc.withFeatureSetups(s.features)...)
 }
 
 // ParallelSequence are feature tests that can be run in parallel when the
@@ -104,11 +106,13 @@ func (p *ParallelSequence) Features() []types.Feature {
 // Validate ensures that features are being run in accordance to label and
 // configuration semantics.
 // Tests labeled as disruptive or slow may not be run in parallel with other
-// tests.
+// tests, and chaos and upgrade tests may only be run in their own modes.
 // Invalid configuration is recorded as a source code bug and can be retrieved
 // with FormatBugs.
 func (p *ParallelSequence) Validate() {
 	for _, f := range p.features {
+		validateFeatureMode(&TestContext, f)
+
 		if f.Labels().Contains(testlabels.Disruptive()) {
 			RecordBug(NewBug(fmt.Sprintf("disruptive tests must not be run in parallel: %q", f.Name()), 1))
 		}
@@ -124,5 +128,48 @@ func (p *ParallelSequence) Validate() {
 func (p *ParallelSequence) Test(t *testing.T, tc *TestContextType) context.Context {
 	// TODO(tvs): Allow parallelism to be optional and the scope of parallelism
 	// to be restricted.
-	return tc.TestEnv.TestInParallel(t, p.features...)
+	return tc.TestEnv.TestInParallel(t, tc.withFeatureSetups(p.features)...)
+}
+
+// withFeatureSetups returns the features with the steps registered with
+// SetupEachFeature prepended to their Setup.
+func (tc *TestContextType) withFeatureSetups(features []types.Feature) []types.Feature {
+	if len(tc.featureSetups) == 0 {
+		return features
+	}
+
+	wrapped := make([]types.Feature, 0, len(features))
+	for _, f := range features {
+		steps := make([]types.Step, 0, len(tc.featureSetups)+len(f.Steps()))
+		for _, fn := range tc.featureSetups {
+			steps = append(steps, setupStep(fn))
+		}
+		wrapped = append(wrapped, &featureWithSetups{Feature: f, steps: append(steps, f.Steps()...)})
+	}
+	return wrapped
+}
+
+// featureWithSetups is a feature with additional steps.
+type featureWithSetups struct {
+	types.Feature
+	steps []types.Step
+}
+
+func (f *featureWithSetups) Steps() []types.Step {
+	return f.steps
+}
+
+// setupStep is an unnamed step run at the Setup level.
+type setupStep types.StepFunc
+
+func (s setupStep) Name() string {
+	return ""
+}
+
+func (s setupStep) Level() types.Level {
+	return types.LevelSetup
+}
+
+func (s setupStep) Func() types.StepFunc {
+	return types.StepFunc(s)
 }
@@ -39,7 +39,7 @@ func KubernetesService() (string, string) {
 // targeted to the Kubernetes Distribution. The return value must be passed
 // into [features.WithLabel].
 func KubernetesDistribution() (string, string) {
-	return kindLabel(kindKubernetesService)
+	return kindLabel(kindKubernetesDistribution)
 }
 
 // WorkloadCluster specifies that a certain test or group of tests are targeted
@@ -87,6 +87,8 @@ const (
 	typeFlaky       = "Flaky"
 	typeDisruptive  = "Disruptive"
 	typeSlow        = "Slow"
+	typeChaos       = "Chaos"
+	typeUpgrade     = "Upgrade"
 )
 
 func typeLabel(v string) (string, string) {
@@ -120,3 +122,19 @@ func Disruptive() (string, string) {
 func Slow() (string, string) {
 	return typeLabel(typeSlow)
 }
+
+// Chaos specifies that a certain test or group of tests inject faults into
+// the system under test and verify that it recovers. Chaos tests only run in
+// chaos mode and never alongside other tests. The return value must be passed
+// into [features.WithLabel].
+func Chaos() (string, string) {
+	return typeLabel(typeChaos)
+}
+
+// Upgrade specifies that a certain test or group of tests belong to the
+// upgrade of a Kubernetes Distribution, either preparing for, performing or
+// verifying it. Upgrade tests only run in upgrade mode and never alongside
+// other tests. The return value must be passed into [features.WithLabel].
+func Upgrade() (string, string) {
+	return typeLabel(typeUpgrade)
+}
@@ -27,6 +27,13 @@ var defaultTimeouts = TimeoutContext{
 	PodDelete:       5 * time.Minute,
 	NodeSchedulable: 30 * time.Minute,
 	ClusterReady:    30 * time.Minute,
+	ClusterDelete:   30 * time.Minute,
+	AddonReady:      10 * time.Minute,
+	LoadBalancer:    10 * time.Minute,
+	ClaimProvision:  5 * time.Minute,
+	PVDelete:        5 * time.Minute,
+	ChaosRecovery:   10 * time.Minute,
+	ClusterUpgrade:  60 * time.Minute,
 }
 
 // TimeoutContext contains timeout settings for several actions.
@@ -46,6 +53,34 @@ type TimeoutContext struct {
 
 	// ClusterReady is how long to wait for a Cluster be ready.
 	ClusterReady time.Duration
+
+	// ClusterDelete is how long to wait for a Cluster and all of its
+	// dependent resources to be deleted.
+	ClusterDelete time.Duration
+
+	// AddonReady is how long to wait for an addon to report that it has been
+	// successfully installed.
+	AddonReady time.Duration
+
+	// LoadBalancer is how long to wait for a load balancer to be provisioned
+	// or removed for a Service.
+	LoadBalancer time.Duration
+
+	// ClaimProvision is how long to wait for a PersistentVolumeClaim to be
+	// bound or resized.
+	ClaimProvision time.Duration
+
+	// PVDelete is how long to wait for a PersistentVolume and the storage
+	// backing it to be deleted.
+	PVDelete time.Duration
+
+	// ChaosRecovery is how long to wait for the system under test to recover
+	// after a fault has been injected.
+	ChaosRecovery time.Duration
+
+	// ClusterUpgrade is how long to wait for every Machine of a Cluster to be
+	// rolled out at a new Kubernetes version.
+	ClusterUpgrade time.Duration
 }
 
 // RegisterTimeoutFlags registers flags related to timeouts
@@ -55,6 +90,13 @@ func RegisterTimeoutFlags(flags *flag.FlagSet, tc *TestContextType) {
 	flags.DurationVar(&tc.timeouts.PodDelete, "pod-delete-timeout", TestContext.timeouts.PodDelete, "Timeout for waiting for a pod to be deleted.")
 	flags.DurationVar(&tc.timeouts.NodeSchedulable, "node-schedulable-timeout", TestContext.timeouts.NodeSchedulable, "Timeout for waiting for a/all nodes to be schedulable.")
 	flags.DurationVar(&tc.timeouts.ClusterReady, "cluster-ready-timeout", TestContext.timeouts.ClusterReady, "Timeout for waiting for a cluster to be ready.")
+	flags.DurationVar(&tc.timeouts.ClusterDelete, "cluster-delete-timeout", TestContext.timeouts.ClusterDelete, "Timeout for waiting for a cluster and its dependent resources to be deleted.")
+	flags.DurationVar(&tc.timeouts.AddonReady, "addon-ready-timeout", TestContext.timeouts.AddonReady, "Timeout for waiting for an addon to be installed.")
+	flags.DurationVar(&tc.timeouts.LoadBalancer, "load-balancer-timeout", TestContext.timeouts.LoadBalancer, "Timeout for waiting for a load balancer to be provisioned or removed.")
+	flags.DurationVar(&tc.timeouts.ClaimProvision, "claim-provision-timeout", TestContext.timeouts.ClaimProvision, "Timeout for waiting for a persistent volume claim to be bound or resized.")
+	flags.DurationVar(&tc.timeouts.PVDelete, "pv-delete-timeout", TestContext.timeouts.PVDelete, "Timeout for waiting for a persistent volume to be deleted.")
+	flags.DurationVar(&tc.timeouts.ChaosRecovery, "chaos-recovery-timeout", TestContext.timeouts.ChaosRecovery, "Timeout for waiting for the system under test to recover from an injected fault.")
+	flags.DurationVar(&tc.timeouts.ClusterUpgrade, "cluster-upgrade-timeout", TestContext.timeouts.ClusterUpgrade, "Timeout for waiting for a cluster to be upgraded to a new Kubernetes version.")
 }
 
 // NewTimeoutContext returns a TimeoutContext with all values set either to
@@ -0,0 +1,56 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/e2e-framework/klient"
+)
+
+// WorkloadClient returns a client for the workload cluster under test. The
+// client is built from the kubeconfig Secret that Cluster API generates for
+// the cluster on the Kubernetes Service, which c must be a client for.
+func (tc *TestContextType) WorkloadClient(ctx context.Context, c klient.Client) (klient.Client, error) {
+	var secret corev1.Secret
+	name := fmt.Sprintf("%s-kubeconfig", tc.ClusterName)
+	if err := c.Resources().Get(ctx, name, tc.ClusterNamespace, &secret); err != nil {
+		return nil, fmt.Errorf("getting kubeconfig Secret %s/%s: %w", tc.ClusterNamespace, name, err)
+	}
+
+	cfg, err := clientcmd.RESTConfigFromKubeConfig(secret.Data["value"])
+	if err != nil {
+		return nil, fmt.Errorf("parsing kubeconfig Secret %s/%s: %w", tc.ClusterNamespace, name, err)
+	}
+
+	return klient.New(cfg)
+}
+
+// SupervisorClient returns a client for the Kubernetes Service managing the
+// workload cluster under test, for use by features that run against the
+// workload cluster.
+func (tc *TestContextType) SupervisorClient() (klient.Client, error) {
+	if tc.SupervisorKubeconfig == "" {
+		return nil, errors.New("-supervisor-kubeconfig is required to access the Kubernetes Service")
+	}
+
+	return klient.NewWithKubeConfigFile(tc.SupervisorKubeconfig)
+}
@@ -25,6 +25,8 @@ import (
 	"sigs.k8s.io/e2e-framework/pkg/envfuncs"
 
 	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/availability"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/events"
 )
 
 func TestMain(m *testing.M) {
@@ -38,8 +40,19 @@ func TestMain(m *testing.M) {
 	framework.TestContext.TestEnv.Setup(
 		envfuncs.CreateNamespace(namespace),
 	)
+
+	// Monitor availability of the cluster under test while disruptive
+	// features run
+	monitor := availability.New(&framework.TestContext)
+	framework.TestContext.TestEnv.BeforeEachFeature(monitor.Start)
+	framework.TestContext.TestEnv.AfterEachFeature(monitor.Stop)
+
+	// Capture Warning events emitted while each feature runs
+	framework.TestContext.SetupEachFeature(events.Record)
+
 	framework.TestContext.TestEnv.Finish(
 		envfuncs.DeleteNamespace(namespace),
+		monitor.Finish,
 	)
 
 	os.Exit(framework.TestContext.TestEnv.Run(m))
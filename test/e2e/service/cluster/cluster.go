@@ -22,21 +22,32 @@ import (
 	"sigs.k8s.io/e2e-framework/pkg/features"
 
 	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/kinds"
 	"github.com/tvs/kubernetes-service-tests/test/e2e/service/features/addons/cloudprovider"
 	"github.com/tvs/kubernetes-service-tests/test/e2e/service/features/addons/cni"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/service/features/addons/csi"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/service/features/capi"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/service/features/deletion"
 )
 
+// ClusterTests runs the Cluster suite on its own.
 func ClusterTests(t *testing.T, tc *framework.TestContextType) {
 	builder := framework.NewTestRunner()
+	WithClusterTests(builder, t, tc)
+	builder.Runner().Test(t, tc)
+}
 
+// WithClusterTests adds the sequences of the Cluster suite to the test run.
+func WithClusterTests(builder *framework.TestRunnerBuilder, t *testing.T, tc *framework.TestContextType) *framework.TestRunnerBuilder {
 	// TODO(tvs): Cluster creation test
 
-	//builder.WithParallelSequence(CAPIResourceTests(t, tc)...)
+	builder.WithParallelSequence(capi.Features(t, tc)...)
 
 	// Run feature tests in parallel
 	feat := []features.Feature{}
 	feat = append(feat, cni.Features(t, tc)...)
 	feat = append(feat, cloudprovider.Features(t, tc)...)
+	feat = append(feat, csi.Features(t, tc)...)
 	builder.WithParallelSequence(feat...)
 
 	// TODO(tvs): Cluster remediation test
@@ -44,7 +55,7 @@ func ClusterTests(t *testing.T, tc *framework.TestContextType) {
 
 	// TODO(tvs): Cluster upgrade test
 
-	// TODO(tvs): Cluster deletion test
+	builder.WithSerialSequence(deletion.Feature(t, tc, kinds.Cluster))
 
-	builder.Runner().Test(t, tc)
+	return builder
 }
@@ -25,12 +25,14 @@ import (
 
 	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
 	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/testlabels"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/service/features/addons"
 )
 
 // Features returns a list of cloud provider test features to be run in a given context
 func Features(t *testing.T, tc *framework.TestContextType) []features.Feature {
 	return []features.Feature{
 		Feature(t, tc),
+		ProviderServiceAccountFeature(t, tc),
 	}
 }
 
@@ -39,12 +41,10 @@ func Feature(t *testing.T, tc *framework.TestContextType) features.Feature {
 	builder := features.New("cloud provider")
 	builder.WithLabel(testlabels.KubernetesService())
 
-	builder.Assess("ClusterBootstrap reports success", func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
-		// TODO(tvs): Add test content
+	builder.Assess("ClusterBootstrap reports success", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		addons.AssertReconciled(ctx, t, c.Client(), tc, addons.CPI, "")
 		return ctx
 	})
 
-	// TODO(tvs): ProviderServiceAccount test
-
 	return builder.Feature()
 }
@@ -0,0 +1,259 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudprovider
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/klient/wait/conditions"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/kinds"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/testlabels"
+)
+
+const (
+	providerServiceAccountPrefix = "e2e-provider"
+
+	// targetNamespace is the workload cluster namespace the token Secret is
+	// synced into. It always exists so the test does not depend on the
+	// cloud provider creating it.
+	targetNamespace = "default"
+)
+
+// providerServiceAccountRules are the permissions requested for the
+// generated ServiceAccount.
+var providerServiceAccountRules = []rbacv1.PolicyRule{{
+	APIGroups: []string{""},
+	Resources: []string{"configmaps"},
+	Verbs:     []string{"get", "list"},
+}}
+
+type providerServiceAccountKey struct{}
+
+// providerServiceAccount contains the ProviderServiceAccount created for the
+// cluster under test and the resources expected to be generated from it.
+type providerServiceAccount struct {
+	psa *unstructured.Unstructured
+
+	serviceAccount *corev1.ServiceAccount
+	role           *rbacv1.Role
+	roleBinding    *rbacv1.RoleBinding
+	targetSecret   *corev1.Secret
+}
+
+// ProviderServiceAccountFeature returns a test feature for the
+// ProviderServiceAccounts reconciled by the cloud provider
+func ProviderServiceAccountFeature(t *testing.T, tc *framework.TestContextType) features.Feature {
+	builder := features.New("cloud provider ProviderServiceAccount")
+	builder.WithLabel(testlabels.KubernetesService())
+
+	builder.Setup(createProviderServiceAccount(tc))
+
+	builder.Assess("ProviderServiceAccount generates RBAC", assertProviderServiceAccountGenerated)
+	builder.Assess("ProviderServiceAccount token is synced", assertProviderServiceAccountSynced(tc))
+	builder.Assess("ProviderServiceAccount deletion removes generated resources", assertProviderServiceAccountDeleted(tc))
+
+	builder.Teardown(deleteProviderServiceAccount)
+
+	return builder.Feature()
+}
+
+// createProviderServiceAccount creates a ProviderServiceAccount referencing
+// the infrastructure cluster of the cluster under test.
+func createProviderServiceAccount(tc *framework.TestContextType) func(context.Context, *testing.T, *envconf.Config) context.Context {
+	return func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		cluster := kinds.New(kinds.Cluster)
+		if err := c.Client().Resources().Get(ctx, tc.ClusterName, tc.ClusterNamespace, cluster); err != nil {
+			t.Fatalf("unable to get Cluster %s/%s: %s", tc.ClusterNamespace, tc.ClusterName, err)
+		}
+		ref, found, err := unstructured.NestedStringMap(cluster.Object, "spec", "infrastructureRef")
+		if err != nil || !found {
+			t.Fatalf("Cluster %s/%s has no infrastructureRef", tc.ClusterNamespace, tc.ClusterName)
+		}
+
+		rules := make([]interface{}, 0, len(providerServiceAccountRules))
+		for i := range providerServiceAccountRules {
+			rule, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&providerServiceAccountRules[i])
+			if err != nil {
+				t.Fatalf("unable to convert policy rule: %s", err)
+			}
+			rules = append(rules, rule)
+		}
+
+		// Both names are random so that a ProviderServiceAccount leaked by an
+		// earlier run does not conflict.
+		name := envconf.RandomName(providerServiceAccountPrefix, 20)
+		targetSecretName := envconf.RandomName(providerServiceAccountPrefix, 20)
+
+		p := &providerServiceAccount{psa: kinds.New(kinds.ProviderServiceAccount)}
+		p.psa.SetName(name)
+		p.psa.SetNamespace(tc.ClusterNamespace)
+		p.psa.Object["spec"] = map[string]interface{}{
+			"ref": map[string]interface{}{
+				"apiVersion": ref["apiVersion"],
+				"kind":       ref["kind"],
+				"name":       ref["name"],
+			},
+			"rules":            rules,
+			"targetNamespace":  targetNamespace,
+			"targetSecretName": targetSecretName,
+		}
+
+		// The ServiceAccount, Role and RoleBinding share a name derived from
+		// the referenced infrastructure cluster.
+		generated := fmt.Sprintf("%s-%s", ref["name"], name)
+		p.serviceAccount = &corev1.ServiceAccount{}
+		p.role = &rbacv1.Role{}
+		p.roleBinding = &rbacv1.RoleBinding{}
+		for _, obj := range []k8s.Object{p.serviceAccount, p.role, p.roleBinding} {
+			obj.SetName(generated)
+			obj.SetNamespace(tc.ClusterNamespace)
+		}
+		p.targetSecret = &corev1.Secret{}
+		p.targetSecret.SetName(targetSecretName)
+		p.targetSecret.SetNamespace(targetNamespace)
+
+		if err := c.Client().Resources().Create(ctx, p.psa); err != nil {
+			t.Fatalf("unable to create ProviderServiceAccount %s/%s: %s", p.psa.GetNamespace(), p.psa.GetName(), err)
+		}
+
+		return context.WithValue(ctx, providerServiceAccountKey{}, p)
+	}
+}
+
+// assertProviderServiceAccountGenerated waits for the ServiceAccount, Role
+// and RoleBinding to be generated for the ProviderServiceAccount.
+func assertProviderServiceAccountGenerated(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+	p := ctx.Value(providerServiceAccountKey{}).(*providerServiceAccount)
+	res := c.Client().Resources()
+
+	waitForMatch(ctx, t, res, p.serviceAccount, func(k8s.Object) bool { return true })
+
+	waitForMatch(ctx, t, res, p.role, func(obj k8s.Object) bool {
+		return equality.Semantic.DeepEqual(obj.(*rbacv1.Role).Rules, providerServiceAccountRules)
+	})
+	if !equality.Semantic.DeepEqual(p.role.Rules, providerServiceAccountRules) {
+		t.Errorf("Role %s/%s has rules %v, expected %v", p.role.Namespace, p.role.Name, p.role.Rules, providerServiceAccountRules)
+	}
+
+	waitForMatch(ctx, t, res, p.roleBinding, func(obj k8s.Object) bool {
+		rb := obj.(*rbacv1.RoleBinding)
+		return rb.RoleRef.Kind == "Role" && rb.RoleRef.Name == p.role.Name &&
+			len(rb.Subjects) == 1 && rb.Subjects[0].Kind == rbacv1.ServiceAccountKind && rb.Subjects[0].Name == p.serviceAccount.Name
+	})
+
+	return ctx
+}
+
+// assertProviderServiceAccountSynced waits for the ServiceAccount token to be
+// synced into the target Secret in the workload cluster.
+func assertProviderServiceAccountSynced(tc *framework.TestContextType) func(context.Context, *testing.T, *envconf.Config) context.Context {
+	return func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		p := ctx.Value(providerServiceAccountKey{}).(*providerServiceAccount)
+
+		workload, err := tc.WorkloadClient(ctx, c.Client())
+		if err != nil {
+			t.Fatalf("unable to create workload cluster client: %s", err)
+		}
+
+		waitForMatch(ctx, t, workload.Resources(), p.targetSecret, func(obj k8s.Object) bool {
+			return len(obj.(*corev1.Secret).Data[corev1.ServiceAccountTokenKey]) > 0
+		})
+
+		return ctx
+	}
+}
+
+// assertProviderServiceAccountDeleted deletes the ProviderServiceAccount and
+// waits for every resource generated from it to be removed.
+func assertProviderServiceAccountDeleted(tc *framework.TestContextType) func(context.Context, *testing.T, *envconf.Config) context.Context {
+	return func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		p := ctx.Value(providerServiceAccountKey{}).(*providerServiceAccount)
+		res := c.Client().Resources()
+
+		workload, err := tc.WorkloadClient(ctx, c.Client())
+		if err != nil {
+			t.Fatalf("unable to create workload cluster client: %s", err)
+		}
+
+		if err := res.Delete(ctx, p.psa); err != nil {
+			t.Fatalf("unable to delete ProviderServiceAccount %s/%s: %s", p.psa.GetNamespace(), p.psa.GetName(), err)
+		}
+
+		for _, obj := range []k8s.Object{p.psa, p.serviceAccount, p.role, p.roleBinding} {
+			waitForDeleted(ctx, t, res, obj)
+		}
+		waitForDeleted(ctx, t, workload.Resources(), p.targetSecret)
+
+		return ctx
+	}
+}
+
+// deleteProviderServiceAccount removes the ProviderServiceAccount if it was
+// not deleted by the feature.
+func deleteProviderServiceAccount(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+	p, ok := ctx.Value(providerServiceAccountKey{}).(*providerServiceAccount)
+	if !ok {
+		return ctx
+	}
+
+	if err := c.Client().Resources().Delete(ctx, p.psa); err != nil && !apierrors.IsNotFound(err) {
+		t.Errorf("unable to delete ProviderServiceAccount %s/%s: %s", p.psa.GetNamespace(), p.psa.GetName(), err)
+	}
+
+	return ctx
+}
+
+func waitForMatch(ctx context.Context, t *testing.T, res *resources.Resources, obj k8s.Object, match func(k8s.Object) bool) {
+	t.Helper()
+
+	err := wait.For(conditions.New(res).ResourceMatch(obj, match),
+		wait.WithContext(ctx),
+		wait.WithTimeout(framework.NewTimeoutContext().AddonReady),
+		wait.WithInterval(framework.PollInterval()),
+		wait.WithImmediate())
+	if err != nil {
+		t.Errorf("%T %s/%s was not generated: %s", obj, obj.GetNamespace(), obj.GetName(), err)
+	}
+}
+
+func waitForDeleted(ctx context.Context, t *testing.T, res *resources.Resources, obj k8s.Object) {
+	t.Helper()
+
+	err := wait.For(conditions.New(res).ResourceDeleted(obj),
+		wait.WithContext(ctx),
+		wait.WithTimeout(framework.NewTimeoutContext().AddonReady),
+		wait.WithInterval(framework.PollInterval()),
+		wait.WithImmediate())
+	if err != nil {
+		t.Errorf("%T %s/%s was not deleted: %s", obj, obj.GetNamespace(), obj.GetName(), err)
+	}
+}
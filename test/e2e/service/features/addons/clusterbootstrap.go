@@ -0,0 +1,152 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package addons contains helpers shared by the addon features that inspect
+// the ClusterBootstrap of the cluster under test.
+package addons
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/e2e-framework/klient"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/kinds"
+)
+
+// Addon identifies the field of a ClusterBootstrap spec that references the
+// package installed for an addon.
+type Addon string
+
+const (
+	// CNI is the container network interface addon.
+	CNI Addon = "cni"
+
+	// CPI is the cloud provider interface addon.
+	CPI Addon = "cpi"
+
+	// CSI is the container storage interface addon.
+	CSI Addon = "csi"
+)
+
+// GetClusterBootstrap returns the ClusterBootstrap of the cluster under test.
+func GetClusterBootstrap(ctx context.Context, c klient.Client, tc *framework.TestContextType) (*unstructured.Unstructured, error) {
+	cb := kinds.New(kinds.ClusterBootstrap)
+	if err := c.Resources().Get(ctx, tc.ClusterName, tc.ClusterNamespace, cb); err != nil {
+		return nil, fmt.Errorf("getting ClusterBootstrap %s/%s: %w", tc.ClusterNamespace, tc.ClusterName, err)
+	}
+	return cb, nil
+}
+
+// PackageRefName returns the name of the package referenced by the
+// ClusterBootstrap for the addon, for example
+// "antrea.tanzu.vmware.com.1.7.2+vmware.1-tkg.1". An empty string is returned
+// if the addon is not configured.
+func PackageRefName(cb *unstructured.Unstructured, addon Addon) string {
+	refName, _, _ := unstructured.NestedString(cb.Object, "spec", string(addon), "refName")
+	return refName
+}
+
+// PackageShortName returns the short name of a package reference, for
+// example "antrea" for "antrea.tanzu.vmware.com.1.7.2+vmware.1-tkg.1".
+func PackageShortName(refName string) string {
+	short, _, _ := strings.Cut(refName, ".")
+	return short
+}
+
+// ConditionType returns the ClusterBootstrap status condition type used to
+// report the reconciliation of the referenced package, for example
+// "Antrea-ReconcileSucceeded" or "Vsphere-Cpi-ReconcileSucceeded".
+func ConditionType(refName string) string {
+	words := strings.Split(PackageShortName(refName), "-")
+	for i, w := range words {
+		if w == "" {
+			continue
+		}
+		r := []rune(w)
+		r[0] = unicode.ToUpper(r[0])
+		words[i] = string(r)
+	}
+	return strings.Join(words, "-") + "-ReconcileSucceeded"
+}
+
+// SkipUnlessInstalled skips the test unless the ClusterBootstrap of the
+// cluster under test references the named package for the addon.
+func SkipUnlessInstalled(ctx context.Context, t *testing.T, c klient.Client, tc *framework.TestContextType, addon Addon, pkg string) {
+	t.Helper()
+
+	cb, err := GetClusterBootstrap(ctx, c, tc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refName := PackageRefName(cb, addon)
+	if refName == "" {
+		t.Skipf("%s is not installed, ClusterBootstrap %s/%s does not reference a %s package",
+			pkg, tc.ClusterNamespace, tc.ClusterName, addon)
+	}
+	if installed := PackageShortName(refName); installed != pkg {
+		t.Skipf("%s is not installed, ClusterBootstrap %s/%s references %s package %q",
+			pkg, tc.ClusterNamespace, tc.ClusterName, addon, refName)
+	}
+}
+
+// AssertReconciled checks that the ClusterBootstrap of the cluster under test
+// references a package for the addon and waits for the package to report that
+// it was reconciled successfully. If pkg is not empty the short name of the
+// referenced package must match it.
+func AssertReconciled(ctx context.Context, t *testing.T, c klient.Client, tc *framework.TestContextType, addon Addon, pkg string) {
+	t.Helper()
+
+	cb, err := GetClusterBootstrap(ctx, c, tc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refName := PackageRefName(cb, addon)
+	switch {
+	case refName == "":
+		t.Fatalf("ClusterBootstrap %s/%s does not reference a %s package", tc.ClusterNamespace, tc.ClusterName, addon)
+	case pkg != "" && PackageShortName(refName) != pkg:
+		t.Fatalf("ClusterBootstrap %s/%s references %s package %q, expected %s",
+			tc.ClusterNamespace, tc.ClusterName, addon, refName, pkg)
+	}
+
+	condType := ConditionType(refName)
+	var last kinds.Condition
+	err = wait.For(func(ctx context.Context) (bool, error) {
+		if err := c.Resources().Get(ctx, cb.GetName(), cb.GetNamespace(), cb); err != nil {
+			return false, err
+		}
+		cond, ok := kinds.GetCondition(cb, condType)
+		last = cond
+		return ok && cond.IsTrue(), nil
+	},
+		wait.WithContext(ctx),
+		wait.WithTimeout(framework.NewTimeoutContext().AddonReady),
+		wait.WithInterval(framework.PollInterval()),
+		wait.WithImmediate())
+	if err != nil {
+		t.Errorf("%s package %q did not reconcile: condition %s is %q: %s: %s: %s",
+			addon, refName, condType, last.Status, last.Reason, last.Message, err)
+	}
+}
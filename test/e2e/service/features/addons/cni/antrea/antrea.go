@@ -24,7 +24,9 @@ import (
 	"sigs.k8s.io/e2e-framework/pkg/features"
 
 	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/cni"
 	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/testlabels"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/service/features/addons"
 )
 
 // Feature returns a test feature for the Antrea CNI
@@ -32,8 +34,13 @@ func Feature(t *testing.T, tc *framework.TestContextType) features.Feature {
 	builder := features.New("antrea")
 	builder.WithLabel(testlabels.KubernetesService())
 
-	builder.Assess("ClusterBootstrap reports success", func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
-		// TODO(tvs): Add test content
+	builder.Setup(func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		addons.SkipUnlessInstalled(ctx, t, c.Client(), tc, addons.CNI, cni.Antrea)
+		return ctx
+	})
+
+	builder.Assess("ClusterBootstrap reports success", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		addons.AssertReconciled(ctx, t, c.Client(), tc, addons.CNI, cni.Antrea)
 		return ctx
 	})
 
@@ -26,7 +26,8 @@ import (
 	"github.com/tvs/kubernetes-service-tests/test/e2e/service/features/addons/cni/calico"
 )
 
-// Features returns a list of CNI test features to be run in a given context
+// Features returns a list of CNI test features to be run in a given context.
+// Only the feature for the installed CNI runs, the others are skipped.
 func Features(t *testing.T, tc *framework.TestContextType) []features.Feature {
 	return []features.Feature{
 		antrea.Feature(t, tc),
@@ -0,0 +1,104 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csi
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/testlabels"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/service/features/addons"
+)
+
+// storageClassQuotaSuffix is the suffix of the ResourceQuota resource names
+// that grant a namespace access to a StorageClass, for example
+// "gold.storageclass.storage.k8s.io/requests.storage".
+const storageClassQuotaSuffix = ".storageclass.storage.k8s.io/requests.storage"
+
+// Features returns a list of CSI test features to be run in a given context
+func Features(t *testing.T, tc *framework.TestContextType) []features.Feature {
+	return []features.Feature{
+		Feature(t, tc),
+	}
+}
+
+// Feature returns a test feature for the CSI storage addon
+func Feature(t *testing.T, tc *framework.TestContextType) features.Feature {
+	builder := features.New("csi")
+	builder.WithLabel(testlabels.KubernetesService())
+
+	builder.Assess("ClusterBootstrap reports success", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		addons.AssertReconciled(ctx, t, c.Client(), tc, addons.CSI, "")
+		return ctx
+	})
+
+	builder.Assess("StorageClasses are synced", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		var quotas corev1.ResourceQuotaList
+		if err := c.Client().Resources(tc.ClusterNamespace).List(ctx, &quotas); err != nil {
+			t.Fatalf("unable to list resource quotas in %s: %s", tc.ClusterNamespace, err)
+		}
+
+		names := storageClassNames(quotas.Items)
+		if len(names) == 0 {
+			t.Skipf("no StorageClasses are assigned to namespace %s", tc.ClusterNamespace)
+		}
+
+		workload, err := tc.WorkloadClient(ctx, c.Client())
+		if err != nil {
+			t.Fatalf("unable to create workload cluster client: %s", err)
+		}
+
+		for _, name := range names {
+			var sc storagev1.StorageClass
+			if err := workload.Resources().Get(ctx, name, "", &sc); err != nil {
+				t.Errorf("StorageClass %s assigned to namespace %s is not synced: %s", name, tc.ClusterNamespace, err)
+			}
+		}
+
+		return ctx
+	})
+
+	return builder.Feature()
+}
+
+// storageClassNames returns the sorted names of the StorageClasses the
+// quotas grant access to.
+func storageClassNames(quotas []corev1.ResourceQuota) []string {
+	seen := map[string]bool{}
+	for _, q := range quotas {
+		for res := range q.Spec.Hard {
+			if name, ok := strings.CutSuffix(string(res), storageClassQuotaSuffix); ok {
+				seen[name] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
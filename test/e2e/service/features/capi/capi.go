@@ -0,0 +1,328 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capi
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/kinds"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/testlabels"
+)
+
+// Features returns a list of Cluster API resource test features to be run
+// against the cluster under test
+func Features(t *testing.T, tc *framework.TestContextType) []features.Feature {
+	return []features.Feature{
+		ClusterFeature(t, tc),
+		ControlPlaneFeature(t, tc),
+		MachineDeploymentFeature(t, tc),
+		MachineSetFeature(t, tc),
+		MachineFeature(t, tc),
+	}
+}
+
+// ClusterFeature returns a test feature for the Cluster API Cluster
+func ClusterFeature(t *testing.T, tc *framework.TestContextType) features.Feature {
+	builder := features.New("capi cluster")
+	builder.WithLabel(testlabels.KubernetesService())
+	builder.WithLabel(testlabels.Conformance())
+
+	builder.Assess("Cluster is Ready", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		cluster := kinds.New(kinds.Cluster)
+		if err := c.Client().Resources().Get(ctx, tc.ClusterName, tc.ClusterNamespace, cluster); err != nil {
+			t.Fatalf("unable to get Cluster %s/%s: %s", tc.ClusterNamespace, tc.ClusterName, err)
+		}
+
+		assertReady(t, cluster)
+		return ctx
+	})
+
+	return builder.Feature()
+}
+
+// ControlPlaneFeature returns a test feature for the KubeadmControlPlane
+// owned by the cluster under test
+func ControlPlaneFeature(t *testing.T, tc *framework.TestContextType) features.Feature {
+	builder := features.New("capi control plane")
+	builder.WithLabel(testlabels.KubernetesService())
+	builder.WithLabel(testlabels.Conformance())
+
+	builder.Assess("KubeadmControlPlane is owned by Cluster", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		for _, kcp := range list(ctx, t, c, tc, kinds.KubeadmControlPlane, 1) {
+			assertOwner(t, &kcp, kinds.Cluster.Kind, false, tc.ClusterName)
+		}
+		return ctx
+	})
+
+	builder.Assess("KubeadmControlPlane is Ready", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		for _, kcp := range list(ctx, t, c, tc, kinds.KubeadmControlPlane, 1) {
+			assertReady(t, &kcp)
+		}
+		return ctx
+	})
+
+	builder.Assess("KubeadmControlPlane replicas match", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		for _, kcp := range list(ctx, t, c, tc, kinds.KubeadmControlPlane, 1) {
+			assertReplicas(t, &kcp, "replicas", "readyReplicas", "updatedReplicas")
+		}
+		return ctx
+	})
+
+	return builder.Feature()
+}
+
+// MachineDeploymentFeature returns a test feature for the MachineDeployments
+// owned by the cluster under test
+func MachineDeploymentFeature(t *testing.T, tc *framework.TestContextType) features.Feature {
+	builder := features.New("capi machine deployments")
+	builder.WithLabel(testlabels.KubernetesService())
+	builder.WithLabel(testlabels.Conformance())
+
+	builder.Assess("MachineDeployments are owned by Cluster", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		for _, md := range list(ctx, t, c, tc, kinds.MachineDeployment, 0) {
+			assertOwner(t, &md, kinds.Cluster.Kind, false, tc.ClusterName)
+		}
+		return ctx
+	})
+
+	builder.Assess("MachineDeployments are Ready", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		for _, md := range list(ctx, t, c, tc, kinds.MachineDeployment, 0) {
+			assertReady(t, &md)
+		}
+		return ctx
+	})
+
+	builder.Assess("MachineDeployment replicas match", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		for _, md := range list(ctx, t, c, tc, kinds.MachineDeployment, 0) {
+			assertReplicas(t, &md, "replicas", "readyReplicas", "updatedReplicas", "availableReplicas")
+		}
+		return ctx
+	})
+
+	return builder.Feature()
+}
+
+// MachineSetFeature returns a test feature for the MachineSets owned by the
+// cluster under test
+func MachineSetFeature(t *testing.T, tc *framework.TestContextType) features.Feature {
+	builder := features.New("capi machine sets")
+	builder.WithLabel(testlabels.KubernetesService())
+	builder.WithLabel(testlabels.Conformance())
+
+	builder.Assess("MachineSets are owned by MachineDeployments", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		owners := names(list(ctx, t, c, tc, kinds.MachineDeployment, 0))
+		for _, ms := range list(ctx, t, c, tc, kinds.MachineSet, 0) {
+			assertOwner(t, &ms, kinds.MachineDeployment.Kind, true, owners...)
+		}
+		return ctx
+	})
+
+	builder.Assess("MachineSets are Ready", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		for _, ms := range list(ctx, t, c, tc, kinds.MachineSet, 0) {
+			assertReady(t, &ms)
+		}
+		return ctx
+	})
+
+	builder.Assess("MachineSet replicas match", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		for _, ms := range list(ctx, t, c, tc, kinds.MachineSet, 0) {
+			assertReplicas(t, &ms, "replicas", "readyReplicas", "availableReplicas")
+		}
+		return ctx
+	})
+
+	return builder.Feature()
+}
+
+// MachineFeature returns a test feature for the Machines owned by the cluster
+// under test
+func MachineFeature(t *testing.T, tc *framework.TestContextType) features.Feature {
+	builder := features.New("capi machines")
+	builder.WithLabel(testlabels.KubernetesService())
+	builder.WithLabel(testlabels.Conformance())
+
+	builder.Assess("Machines are owned by a control plane or MachineSet", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		controlPlanes := names(list(ctx, t, c, tc, kinds.KubeadmControlPlane, 1))
+		machineSets := names(list(ctx, t, c, tc, kinds.MachineSet, 0))
+
+		for _, m := range list(ctx, t, c, tc, kinds.Machine, 1) {
+			if _, ok := m.GetLabels()["cluster.x-k8s.io/control-plane"]; ok {
+				assertOwner(t, &m, kinds.KubeadmControlPlane.Kind, true, controlPlanes...)
+			} else {
+				assertOwner(t, &m, kinds.MachineSet.Kind, true, machineSets...)
+			}
+		}
+		return ctx
+	})
+
+	builder.Assess("Machines are Ready", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		for _, m := range list(ctx, t, c, tc, kinds.Machine, 1) {
+			assertReady(t, &m)
+		}
+		return ctx
+	})
+
+	builder.Assess("Machine providerIDs match workload Nodes", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		workload, err := tc.WorkloadClient(ctx, c.Client())
+		if err != nil {
+			t.Fatalf("unable to create workload cluster client: %s", err)
+		}
+
+		var nodes corev1.NodeList
+		if err := workload.Resources().List(ctx, &nodes); err != nil {
+			t.Fatalf("unable to list workload cluster nodes: %s", err)
+		}
+		providerIDs := make(map[string]string, len(nodes.Items))
+		for _, n := range nodes.Items {
+			providerIDs[n.Name] = n.Spec.ProviderID
+		}
+
+		for _, m := range list(ctx, t, c, tc, kinds.Machine, 1) {
+			providerID, _, _ := unstructured.NestedString(m.Object, "spec", "providerID")
+			nodeName, _, _ := unstructured.NestedString(m.Object, "status", "nodeRef", "name")
+			if providerID == "" {
+				t.Errorf("Machine %s has no providerID", m.GetName())
+				continue
+			}
+			if nodeName == "" {
+				t.Errorf("Machine %s has no nodeRef", m.GetName())
+				continue
+			}
+
+			nodeProviderID, ok := providerIDs[nodeName]
+			switch {
+			case !ok:
+				t.Errorf("Machine %s references Node %s which does not exist", m.GetName(), nodeName)
+			case nodeProviderID != providerID:
+				t.Errorf("Machine %s has providerID %q but Node %s has providerID %q",
+					m.GetName(), providerID, nodeName, nodeProviderID)
+			}
+		}
+		return ctx
+	})
+
+	return builder.Feature()
+}
+
+// list returns the objects of the given kind belonging to the cluster under
+// test, failing the test if fewer than min are found.
+func list(ctx context.Context, t *testing.T, c *envconf.Config, tc *framework.TestContextType, gvk schema.GroupVersionKind, min int) []unstructured.Unstructured {
+	t.Helper()
+
+	objs := kinds.NewList(gvk)
+	err := c.Client().Resources(tc.ClusterNamespace).List(ctx, objs,
+		resources.WithLabelSelector(
+			labels.FormatLabels(
+				map[string]string{kinds.ClusterNameLabel: tc.ClusterName},
+			)))
+	if err != nil {
+		t.Fatalf("unexpected error listing %s: %s", gvk.Kind, err)
+	}
+
+	if len(objs.Items) < min {
+		t.Fatalf("expected at least %d %s for cluster %s/%s, found %d",
+			min, gvk.Kind, tc.ClusterNamespace, tc.ClusterName, len(objs.Items))
+	}
+
+	return objs.Items
+}
+
+func names(objs []unstructured.Unstructured) []string {
+	n := make([]string, 0, len(objs))
+	for _, o := range objs {
+		n = append(n, o.GetName())
+	}
+	return n
+}
+
+// assertOwner checks that obj has an owner reference of the given kind to one
+// of the named owners, and that the owner is its controller if required.
+// Cluster API only sets controller references for objects it reconciles
+// through their owner, not for the references to the Cluster.
+func assertOwner(t *testing.T, obj *unstructured.Unstructured, kind string, controller bool, owners ...string) {
+	t.Helper()
+
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.Kind != kind {
+			continue
+		}
+		for _, o := range owners {
+			if ref.Name == o {
+				if controller && (ref.Controller == nil || !*ref.Controller) {
+					t.Errorf("%s %s is owned by %s %s but it is not the controller",
+						obj.GetKind(), obj.GetName(), kind, ref.Name)
+				}
+				return
+			}
+		}
+	}
+
+	t.Errorf("%s %s has no owner reference to %s %v", obj.GetKind(), obj.GetName(), kind, owners)
+}
+
+// assertReady checks that obj reports at least one readiness condition, such
+// as Ready or MachinesReady, and that every readiness condition is true.
+func assertReady(t *testing.T, obj *unstructured.Unstructured) {
+	t.Helper()
+
+	found := false
+	for _, cond := range kinds.Conditions(obj) {
+		if !strings.HasSuffix(cond.Type, "Ready") {
+			continue
+		}
+		found = true
+		if !cond.IsTrue() {
+			t.Errorf("%s %s condition %s is %s: %s: %s",
+				obj.GetKind(), obj.GetName(), cond.Type, cond.Status, cond.Reason, cond.Message)
+		}
+	}
+
+	if !found {
+		t.Errorf("%s %s has no Ready conditions", obj.GetKind(), obj.GetName())
+	}
+}
+
+// assertReplicas checks that the desired replica count of obj is reflected in
+// each of the given status replica counts. The available counts differ
+// between kinds, so callers name the ones that apply.
+func assertReplicas(t *testing.T, obj *unstructured.Unstructured, fields ...string) {
+	t.Helper()
+
+	desired, found, err := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+	if err != nil || !found {
+		t.Errorf("%s %s has no desired replica count", obj.GetKind(), obj.GetName())
+		return
+	}
+
+	for _, field := range fields {
+		observed, _, _ := unstructured.NestedInt64(obj.Object, "status", field)
+		if observed != desired {
+			t.Errorf("%s %s has %d %s, expected %d", obj.GetKind(), obj.GetName(), observed, field, desired)
+		}
+	}
+}
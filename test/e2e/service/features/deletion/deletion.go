@@ -0,0 +1,236 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deletion
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/klient/wait/conditions"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/kinds"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/testlabels"
+)
+
+type recordKey struct{}
+
+// record contains the resources belonging to the cluster under test that
+// were found before it was deleted, and the time by which all of them must
+// be gone.
+type record struct {
+	deadline time.Time
+
+	machines      []k8s.Object
+	infraMachines []k8s.Object
+	secrets       []k8s.Object
+	bootstraps    []k8s.Object
+	loadBalancers []k8s.Object
+}
+
+// Feature returns a test feature that deletes the cluster under test through
+// the given kind, either a Cluster or a TanzuKubernetesCluster, and verifies
+// that every dependent resource is garbage collected.
+func Feature(t *testing.T, tc *framework.TestContextType, gvk schema.GroupVersionKind) features.Feature {
+	builder := features.New(fmt.Sprintf("%s deletion", gvk.Kind))
+	builder.WithLabel(testlabels.KubernetesService())
+	builder.WithLabel(testlabels.Disruptive())
+	builder.WithLabel(testlabels.Slow())
+
+	builder.Setup(func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		r, err := recordResources(ctx, c.Client().Resources(tc.ClusterNamespace), tc)
+		if err != nil {
+			t.Fatalf("unable to record resources for cluster %s/%s: %s", tc.ClusterNamespace, tc.ClusterName, err)
+		}
+
+		return context.WithValue(ctx, recordKey{}, r)
+	})
+
+	builder.Assess(fmt.Sprintf("%s is deleted", gvk.Kind), func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		r := ctx.Value(recordKey{}).(*record)
+		res := c.Client().Resources(tc.ClusterNamespace)
+
+		obj := kinds.New(gvk)
+		if err := res.Get(ctx, tc.ClusterName, tc.ClusterNamespace, obj); err != nil {
+			t.Fatalf("unable to get %s %s/%s: %s", gvk.Kind, tc.ClusterNamespace, tc.ClusterName, err)
+		}
+
+		r.deadline = time.Now().Add(framework.NewTimeoutContext().ClusterDelete)
+		if err := res.Delete(ctx, obj); err != nil {
+			t.Fatalf("unable to delete %s %s/%s: %s", gvk.Kind, tc.ClusterNamespace, tc.ClusterName, err)
+		}
+
+		waitForDeletion(ctx, t, res, r.deadline, obj)
+		return ctx
+	})
+
+	builder.Assess("Machines are deleted", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		r := ctx.Value(recordKey{}).(*record)
+		waitForDeletion(ctx, t, c.Client().Resources(tc.ClusterNamespace), r.deadline, r.machines...)
+		return ctx
+	})
+
+	builder.Assess("Infrastructure machines are deleted", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		r := ctx.Value(recordKey{}).(*record)
+		waitForDeletion(ctx, t, c.Client().Resources(tc.ClusterNamespace), r.deadline, r.infraMachines...)
+		return ctx
+	})
+
+	builder.Assess("Kubeconfig and CA Secrets are deleted", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		r := ctx.Value(recordKey{}).(*record)
+		waitForDeletion(ctx, t, c.Client().Resources(tc.ClusterNamespace), r.deadline, r.secrets...)
+		return ctx
+	})
+
+	builder.Assess("ClusterBootstrap is deleted", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		r := ctx.Value(recordKey{}).(*record)
+		waitForDeletion(ctx, t, c.Client().Resources(tc.ClusterNamespace), r.deadline, r.bootstraps...)
+		return ctx
+	})
+
+	builder.Assess("LoadBalancer Services are deleted", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		r := ctx.Value(recordKey{}).(*record)
+		waitForDeletion(ctx, t, c.Client().Resources(tc.ClusterNamespace), r.deadline, r.loadBalancers...)
+		return ctx
+	})
+
+	return builder.Feature()
+}
+
+// recordResources finds the resources that are expected to be removed when
+// the cluster under test is deleted.
+func recordResources(ctx context.Context, res *resources.Resources, tc *framework.TestContextType) (*record, error) {
+	r := &record{}
+	selector := resources.WithLabelSelector(labels.FormatLabels(map[string]string{kinds.ClusterNameLabel: tc.ClusterName}))
+
+	machines := kinds.NewList(kinds.Machine)
+	if err := res.List(ctx, machines, selector); err != nil {
+		return nil, fmt.Errorf("listing Machines: %w", err)
+	}
+	for i := range machines.Items {
+		m := &machines.Items[i]
+		r.machines = append(r.machines, m)
+
+		ref, found, err := unstructured.NestedStringMap(m.Object, "spec", "infrastructureRef")
+		if err != nil || !found {
+			return nil, fmt.Errorf("machine %s has no infrastructureRef", m.GetName())
+		}
+		infra := kinds.New(schema.FromAPIVersionAndKind(ref["apiVersion"], ref["kind"]))
+		infra.SetName(ref["name"])
+		infra.SetNamespace(m.GetNamespace())
+		r.infraMachines = append(r.infraMachines, infra)
+	}
+
+	for _, suffix := range []string{"kubeconfig", "ca"} {
+		s := &corev1.Secret{}
+		s.SetName(fmt.Sprintf("%s-%s", tc.ClusterName, suffix))
+		s.SetNamespace(tc.ClusterNamespace)
+		r.secrets = append(r.secrets, s)
+	}
+
+	bootstrap := kinds.New(kinds.ClusterBootstrap)
+	err := res.Get(ctx, tc.ClusterName, tc.ClusterNamespace, bootstrap)
+	switch {
+	case err == nil:
+		r.bootstraps = append(r.bootstraps, bootstrap)
+	case !apierrors.IsNotFound(err):
+		return nil, fmt.Errorf("getting ClusterBootstrap: %w", err)
+	}
+
+	// Only the cluster name label is matched, as other clusters in the
+	// namespace may share the name as a prefix.
+	var services corev1.ServiceList
+	err = res.List(ctx, &services,
+		resources.WithLabelSelector(
+			labels.FormatLabels(
+				map[string]string{kinds.ClusterNameLabel: tc.ClusterName},
+			)))
+	if err != nil {
+		return nil, fmt.Errorf("listing Services: %w", err)
+	}
+	for i := range services.Items {
+		s := &services.Items[i]
+		if s.Spec.Type == corev1.ServiceTypeLoadBalancer {
+			r.loadBalancers = append(r.loadBalancers, s)
+		}
+	}
+
+	return r, nil
+}
+
+// waitForDeletion waits until each of the objects is deleted or the deadline
+// passes. Any objects remaining after the deadline are reported along with
+// the finalizers blocking their removal.
+func waitForDeletion(ctx context.Context, t *testing.T, res *resources.Resources, deadline time.Time, objs ...k8s.Object) {
+	t.Helper()
+
+	for _, obj := range objs {
+		err := waitForObjectDeletion(ctx, res, deadline, obj)
+		if err == nil {
+			continue
+		}
+
+		kind := fmt.Sprintf("%T", obj)
+		if gvk, gvkErr := apiutil.GVKForObject(obj, res.GetScheme()); gvkErr == nil {
+			kind = gvk.Kind
+		}
+		if len(obj.GetFinalizers()) > 0 {
+			t.Errorf("%s %s/%s not deleted, stuck on finalizers %v: %s",
+				kind, obj.GetNamespace(), obj.GetName(), obj.GetFinalizers(), err)
+		} else {
+			t.Errorf("%s %s/%s not deleted: %s", kind, obj.GetNamespace(), obj.GetName(), err)
+		}
+	}
+}
+
+// waitForObjectDeletion waits until the object is deleted or the deadline
+// passes, refreshing the object while it remains. The deadline is shared by
+// every assessment, so once earlier ones have used it up the object is only
+// checked once.
+func waitForObjectDeletion(ctx context.Context, res *resources.Resources, deadline time.Time, obj k8s.Object) error {
+	remaining := time.Until(deadline)
+	if remaining > 0 {
+		return wait.For(conditions.New(res).ResourceDeleted(obj),
+			wait.WithContext(ctx),
+			wait.WithTimeout(remaining),
+			wait.WithInterval(framework.PollInterval()),
+			wait.WithImmediate())
+	}
+
+	err := res.Get(ctx, obj.GetName(), obj.GetNamespace(), obj)
+	switch {
+	case apierrors.IsNotFound(err):
+		return nil
+	case err != nil:
+		return err
+	default:
+		return fmt.Errorf("deletion deadline passed %s ago", (-remaining).Round(time.Second))
+	}
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tkr contains features that verify the TanzuKubernetesReleases
+// available on the Kubernetes Service.
+package tkr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/version"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/kinds"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/testlabels"
+)
+
+const (
+	// resolveAnnotation requests that the TanzuKubernetesRelease of a Cluster
+	// is resolved from the version in its topology.
+	resolveAnnotation = "run.tanzu.vmware.com/resolve-tkr"
+
+	// clusterClass is the ClusterClass provided by the Kubernetes Service in
+	// every namespace.
+	clusterClass = "tanzukubernetescluster"
+)
+
+type releasesKey struct{}
+
+// Features returns a list of TanzuKubernetesRelease test features to be run
+// in a given context
+func Features(t *testing.T, tc *framework.TestContextType) []features.Feature {
+	return []features.Feature{
+		Feature(t, tc),
+	}
+}
+
+// Feature returns a test feature for the TanzuKubernetesReleases available on
+// the Kubernetes Service
+func Feature(t *testing.T, tc *framework.TestContextType) features.Feature {
+	builder := features.New("tanzukubernetesrelease")
+	builder.WithLabel(testlabels.KubernetesService())
+
+	builder.Setup(func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		releases := kinds.NewList(kinds.TanzuKubernetesRelease)
+		if err := c.Client().Resources().List(ctx, releases); err != nil {
+			t.Fatalf("unable to list TanzuKubernetesReleases: %s", err)
+		}
+		if len(releases.Items) == 0 {
+			t.Fatal("no TanzuKubernetesReleases found, check the content library subscription")
+		}
+
+		return context.WithValue(ctx, releasesKey{}, releases.Items)
+	})
+
+	builder.Assess("TanzuKubernetesReleases report conditions", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		for _, tkr := range ctx.Value(releasesKey{}).([]unstructured.Unstructured) {
+			ready, hasReady := kinds.GetCondition(&tkr, "Ready")
+			compatible, hasCompatible := kinds.GetCondition(&tkr, "Compatible")
+
+			switch {
+			case !hasReady || !hasCompatible:
+				t.Errorf("TanzuKubernetesRelease %s is missing its Ready or Compatible condition", tkr.GetName())
+			case !compatible.IsTrue():
+				t.Logf("TanzuKubernetesRelease %s is not compatible: %s: %s", tkr.GetName(), compatible.Reason, compatible.Message)
+			case !ready.IsTrue():
+				t.Errorf("TanzuKubernetesRelease %s is compatible but not ready: %s: %s", tkr.GetName(), ready.Reason, ready.Message)
+			}
+		}
+		return ctx
+	})
+
+	builder.Assess("OSImages exist", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		for _, tkr := range ctx.Value(releasesKey{}).([]unstructured.Unstructured) {
+			images, _, _ := unstructured.NestedSlice(tkr.Object, "spec", "osImages")
+			if len(images) == 0 {
+				t.Errorf("TanzuKubernetesRelease %s does not reference any OSImages", tkr.GetName())
+				continue
+			}
+
+			for _, raw := range images {
+				ref, _ := raw.(map[string]interface{})
+				name, _, _ := unstructured.NestedString(ref, "name")
+
+				err := c.Client().Resources().Get(ctx, name, "", kinds.New(kinds.OSImage))
+				switch {
+				case apierrors.IsNotFound(err):
+					t.Errorf("TanzuKubernetesRelease %s references OSImage %q, which does not exist", tkr.GetName(), name)
+				case err != nil:
+					t.Errorf("unable to get OSImage %q for TanzuKubernetesRelease %s: %s", name, tkr.GetName(), err)
+				}
+			}
+		}
+		return ctx
+	})
+
+	builder.Assess("Default TanzuKubernetesRelease resolves", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		if tc.ClusterNamespace == "" {
+			t.Skip("-cluster-namespace is required to resolve a TanzuKubernetesRelease")
+		}
+
+		latest, err := latestUsable(ctx.Value(releasesKey{}).([]unstructured.Unstructured))
+		if err != nil {
+			t.Fatal(err)
+		}
+		requested := fmt.Sprintf("v%d.%d", latest.Major(), latest.Minor())
+
+		// The Cluster is only created as a dry run, so that the resolution
+		// done by the admission webhooks can be inspected without
+		// provisioning anything.
+		cluster := newCluster(envconf.RandomName("tkr-resolve", 20), tc.ClusterNamespace, requested)
+		err = c.Client().Resources().Create(ctx, cluster, func(o *metav1.CreateOptions) {
+			o.DryRun = []string{metav1.DryRunAll}
+		})
+		if err != nil {
+			t.Fatalf("unable to create Cluster for version %s: %s", requested, err)
+		}
+
+		name := cluster.GetLabels()[kinds.TanzuKubernetesReleaseLabel]
+		if name == "" {
+			t.Fatalf("version %s did not resolve to a TanzuKubernetesRelease", requested)
+		}
+		tkr := kinds.New(kinds.TanzuKubernetesRelease)
+		if err := c.Client().Resources().Get(ctx, name, "", tkr); err != nil {
+			t.Fatalf("unable to get resolved TanzuKubernetesRelease %s: %s", name, err)
+		}
+		if !isUsable(tkr) {
+			t.Errorf("version %s resolved to TanzuKubernetesRelease %s, which is not ready and compatible", requested, name)
+		}
+
+		tkrVersion, _, _ := unstructured.NestedString(tkr.Object, "spec", "kubernetes", "version")
+		clusterVersion, _, _ := unstructured.NestedString(cluster.Object, "spec", "topology", "version")
+		if !strings.HasPrefix(tkrVersion, requested+".") {
+			t.Errorf("version %s resolved to TanzuKubernetesRelease %s with version %s", requested, name, tkrVersion)
+		}
+		if clusterVersion != tkrVersion {
+			t.Errorf("Cluster version resolved to %s, expected %s from TanzuKubernetesRelease %s", clusterVersion, tkrVersion, name)
+		}
+
+		return ctx
+	})
+
+	return builder.Feature()
+}
+
+// isUsable reports whether the TanzuKubernetesRelease is both ready and
+// compatible with the Kubernetes Service.
+func isUsable(tkr *unstructured.Unstructured) bool {
+	ready, _ := kinds.GetCondition(tkr, "Ready")
+	compatible, _ := kinds.GetCondition(tkr, "Compatible")
+	return ready.IsTrue() && compatible.IsTrue()
+}
+
+// latestUsable returns the newest Kubernetes version of the usable
+// TanzuKubernetesReleases.
+func latestUsable(releases []unstructured.Unstructured) (*version.Version, error) {
+	var latest *version.Version
+	for i := range releases {
+		if !isUsable(&releases[i]) {
+			continue
+		}
+
+		raw, _, _ := unstructured.NestedString(releases[i].Object, "spec", "kubernetes", "version")
+		v, err := version.ParseSemantic(raw)
+		if err != nil {
+			return nil, fmt.Errorf("TanzuKubernetesRelease %s has invalid version %q: %w", releases[i].GetName(), raw, err)
+		}
+		if latest == nil || latest.LessThan(v) {
+			latest = v
+		}
+	}
+
+	if latest == nil {
+		return nil, fmt.Errorf("no ready and compatible TanzuKubernetesReleases found")
+	}
+	return latest, nil
+}
+
+// newCluster returns a Cluster that only specifies a version and asks for its
+// TanzuKubernetesRelease to be resolved.
+func newCluster(name, namespace, v string) *unstructured.Unstructured {
+	cluster := kinds.New(kinds.Cluster)
+	cluster.SetName(name)
+	cluster.SetNamespace(namespace)
+	cluster.SetAnnotations(map[string]string{resolveAnnotation: ""})
+	cluster.Object["spec"] = map[string]interface{}{
+		"topology": map[string]interface{}{
+			"class":   clusterClass,
+			"version": v,
+			"controlPlane": map[string]interface{}{
+				"replicas": int64(1),
+			},
+		},
+	}
+	return cluster
+}
@@ -20,8 +20,31 @@ import (
 	"testing"
 
 	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/service/cluster"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/service/tanzukubernetescluster"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/service/tanzukubernetesrelease"
 )
 
+// ServiceTests composes the Kubernetes Service suites selected with
+// -service-suites into a single test run. TanzuKubernetesReleases are
+// verified first so that broken content is reported before any cluster
+// lifecycle tests run.
 func ServiceTests(t *testing.T, tc *framework.TestContextType) {
-	// TODO(tvs): Invoke Cluster, TKC, TKR, etc. tests
+	if len(tc.ServiceSuites) == 0 {
+		return
+	}
+
+	builder := framework.NewTestRunner()
+
+	if tc.RunsServiceSuite(framework.SuiteTanzuKubernetesRelease) {
+		tanzukubernetesrelease.WithTanzuKubernetesReleaseTests(builder, t, tc)
+	}
+	if tc.RunsServiceSuite(framework.SuiteCluster) {
+		cluster.WithClusterTests(builder, t, tc)
+	}
+	if tc.RunsServiceSuite(framework.SuiteTanzuKubernetesCluster) {
+		tanzukubernetescluster.WithTanzuKubernetesClusterTests(builder, t, tc)
+	}
+
+	builder.Runner().Test(t, tc)
 }
@@ -22,22 +22,39 @@ import (
 	"sigs.k8s.io/e2e-framework/pkg/features"
 
 	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/kinds"
 	"github.com/tvs/kubernetes-service-tests/test/e2e/service/features/addons/cloudprovider"
 	"github.com/tvs/kubernetes-service-tests/test/e2e/service/features/addons/cni"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/service/features/addons/csi"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/service/features/capi"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/service/features/deletion"
 )
 
+// TanzuKubernetesClusterTests runs the TanzuKubernetesCluster suite on its
+// own.
 func TanzuKubernetesClusterTests(t *testing.T, tc *framework.TestContextType) {
 	builder := framework.NewTestRunner()
+	WithTanzuKubernetesClusterTests(builder, t, tc)
+	builder.Runner().Test(t, tc)
+}
+
+// WithTanzuKubernetesClusterTests adds the sequences of the
+// TanzuKubernetesCluster suite to the test run. The suite targets the
+// cluster selected with -tkc-name and -tkc-namespace, so that it can run
+// alongside the Cluster suite.
+func WithTanzuKubernetesClusterTests(builder *framework.TestRunnerBuilder, t *testing.T, tc *framework.TestContextType) *framework.TestRunnerBuilder {
+	tc = tc.ForTanzuKubernetesCluster()
 
 	// TODO(tvs): TanzuKubernetesCluster creation test
 	//builder.WithSerialSequence(CreateClusterTests(t, tc))
 
-	//builder.WithParallelSequence(CAPIResourceTests(t, tc)...)
+	builder.WithParallelSequence(capi.Features(t, tc)...)
 
 	// Run feature tests in parallel
 	feat := []features.Feature{}
 	feat = append(feat, cni.Features(t, tc)...)
 	feat = append(feat, cloudprovider.Features(t, tc)...)
+	feat = append(feat, csi.Features(t, tc)...)
 	builder.WithParallelSequence(feat...)
 
 	// TODO(tvs): Cluster scale test
@@ -48,7 +65,7 @@ func TanzuKubernetesClusterTests(t *testing.T, tc *framework.TestContextType) {
 
 	// TODO(tvs): Cluster upgrade test
 
-	// TODO(tvs): Cluster deletion test
+	builder.WithSerialSequence(deletion.Feature(t, tc, kinds.TanzuKubernetesCluster))
 
-	builder.Runner().Test(t, tc)
+	return builder
 }
@@ -0,0 +1,40 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tanzukubernetesrelease
+
+import (
+	"testing"
+
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/service/features/tkr"
+)
+
+// TanzuKubernetesReleaseTests runs the TanzuKubernetesRelease suite on its
+// own.
+func TanzuKubernetesReleaseTests(t *testing.T, tc *framework.TestContextType) {
+	builder := framework.NewTestRunner()
+	WithTanzuKubernetesReleaseTests(builder, t, tc)
+	builder.Runner().Test(t, tc)
+}
+
+// WithTanzuKubernetesReleaseTests adds the sequences of the
+// TanzuKubernetesRelease suite to the test run.
+func WithTanzuKubernetesReleaseTests(builder *framework.TestRunnerBuilder, t *testing.T, tc *framework.TestContextType) *framework.TestRunnerBuilder {
+	builder.WithParallelSequence(tkr.Features(t, tc)...)
+
+	return builder
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/e2e-framework/klient"
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/availability"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/continuity"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/kinds"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/node"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/testlabels"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/upgrade/features/version"
+)
+
+type upgradeKey struct{}
+
+// upgrade contains the client for the Kubernetes Service managing the
+// cluster under test and the time by which the upgrade must be complete.
+type upgrade struct {
+	supervisor klient.Client
+	deadline   time.Time
+}
+
+// Feature returns a test feature that upgrades the cluster under test from
+// -upgrade-from to -upgrade-to by updating the version of its Cluster
+// topology on the Kubernetes Service. Workloads deployed before the upgrade
+// must keep their data and availability throughout it
+func Feature(t *testing.T, tc *framework.TestContextType) features.Feature {
+	builder := features.New("cluster upgrade")
+	builder.WithLabel(testlabels.KubernetesDistribution())
+	builder.WithLabel(testlabels.Upgrade())
+	builder.WithLabel(testlabels.Disruptive())
+	builder.WithLabel(testlabels.Slow())
+
+	builder.Setup(func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		supervisor, err := tc.SupervisorClient()
+		if err != nil {
+			t.Fatalf("unable to create Kubernetes Service client: %s", err)
+		}
+
+		cluster := kinds.New(kinds.Cluster)
+		if err := supervisor.Resources().Get(ctx, tc.ClusterName, tc.ClusterNamespace, cluster); err != nil {
+			t.Fatalf("unable to get Cluster %s/%s: %s", tc.ClusterNamespace, tc.ClusterName, err)
+		}
+
+		// The topology version carries the distribution build metadata, which
+		// -upgrade-from may leave out, so only the release is compared.
+		current, _, _ := unstructured.NestedString(cluster.Object, "spec", "topology", "version")
+		if err := version.Matches(current, tc.UpgradeFrom); err != nil {
+			t.Fatalf("Cluster %s/%s topology version does not match -upgrade-from: %s",
+				tc.ClusterNamespace, tc.ClusterName, err)
+		}
+
+		return context.WithValue(ctx, upgradeKey{}, &upgrade{supervisor: supervisor})
+	})
+
+	hooks := continuity.New(tc, continuity.Direct)
+	builder.Setup(hooks.Before)
+
+	builder.Assess("Cluster topology version is updated", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		u := ctx.Value(upgradeKey{}).(*upgrade)
+
+		patch, err := json.Marshal(map[string]any{
+			"spec": map[string]any{
+				"topology": map[string]any{"version": tc.UpgradeTo},
+			},
+		})
+		if err != nil {
+			t.Fatalf("unable to marshal patch: %s", err)
+		}
+
+		cluster := kinds.New(kinds.Cluster)
+		cluster.SetName(tc.ClusterName)
+		cluster.SetNamespace(tc.ClusterNamespace)
+
+		u.deadline = time.Now().Add(framework.NewTimeoutContext().ClusterUpgrade)
+		err = u.supervisor.Resources().Patch(ctx, cluster, k8s.Patch{PatchType: types.MergePatchType, Data: patch})
+		if err != nil {
+			t.Fatalf("unable to update Cluster %s/%s to version %s: %s",
+				tc.ClusterNamespace, tc.ClusterName, tc.UpgradeTo, err)
+		}
+		t.Logf("upgrading Cluster %s/%s from %s to %s", tc.ClusterNamespace, tc.ClusterName, tc.UpgradeFrom, tc.UpgradeTo)
+
+		return ctx
+	})
+
+	builder.Assess("Machines are rolled out", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		u := ctx.Value(upgradeKey{}).(*upgrade)
+
+		var pending []string
+		err := wait.For(func(ctx context.Context) (bool, error) {
+			pending = pendingMachines(ctx, u.supervisor, tc)
+			return len(pending) == 0, nil
+		},
+			wait.WithContext(ctx),
+			wait.WithTimeout(time.Until(u.deadline)),
+			wait.WithInterval(framework.PollInterval()),
+			wait.WithImmediate())
+		if err != nil {
+			t.Fatalf("Machines of Cluster %s/%s are not upgraded to %s: %s: %s",
+				tc.ClusterNamespace, tc.ClusterName, tc.UpgradeTo, strings.Join(pending, ", "), err)
+		}
+		return ctx
+	})
+
+	builder.Assess("Nodes are upgraded", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		u := ctx.Value(upgradeKey{}).(*upgrade)
+
+		var pending []string
+		err := wait.For(func(ctx context.Context) (bool, error) {
+			nodes, err := node.List(ctx, c.Client())
+			if err != nil {
+				pending = []string{err.Error()}
+				return false, nil
+			}
+
+			pending = pending[:0]
+			for _, n := range nodes {
+				if err := version.Matches(n.Status.NodeInfo.KubeletVersion, tc.UpgradeTo); err != nil {
+					pending = append(pending, fmt.Sprintf("node %s: %s", n.Name, err))
+				} else if !node.IsReady(&n) {
+					pending = append(pending, fmt.Sprintf("node %s is not ready", n.Name))
+				}
+			}
+			return len(pending) == 0, nil
+		},
+			wait.WithContext(ctx),
+			wait.WithTimeout(time.Until(u.deadline)),
+			wait.WithInterval(framework.PollInterval()),
+			wait.WithImmediate())
+		if err != nil {
+			t.Errorf("nodes are not upgraded to %s: %s: %s", tc.UpgradeTo, strings.Join(pending, ", "), err)
+		}
+		return ctx
+	})
+
+	builder.Assess("Workloads are intact", hooks.After)
+
+	builder.Assess("API server downtime is tolerated", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		availability.AssertMaxDowntime(ctx, t, availability.APIServer, tc.MaxUpgradeDowntime)
+		return ctx
+	})
+
+	builder.Teardown(hooks.Cleanup)
+
+	return builder.Feature()
+}
+
+// pendingMachines returns a description of every Machine of the cluster under
+// test that is not yet ready at the target version. A Cluster without any
+// Machines is never considered upgraded.
+func pendingMachines(ctx context.Context, supervisor klient.Client, tc *framework.TestContextType) []string {
+	machines := kinds.NewList(kinds.Machine)
+	err := supervisor.Resources(tc.ClusterNamespace).List(ctx, machines,
+		resources.WithLabelSelector(
+			labels.FormatLabels(
+				map[string]string{kinds.ClusterNameLabel: tc.ClusterName},
+			)))
+	if err != nil {
+		return []string{fmt.Sprintf("unable to list Machines: %s", err)}
+	}
+	if len(machines.Items) == 0 {
+		return []string{"no Machines found"}
+	}
+
+	var pending []string
+	for i := range machines.Items {
+		m := &machines.Items[i]
+		v, _, _ := unstructured.NestedString(m.Object, "spec", "version")
+		if v != tc.UpgradeTo {
+			pending = append(pending, fmt.Sprintf("Machine %s is at version %s", m.GetName(), v))
+			continue
+		}
+		if cond, ok := kinds.GetCondition(m, "Ready"); !ok || !cond.IsTrue() {
+			pending = append(pending, fmt.Sprintf("Machine %s is not Ready", m.GetName()))
+		}
+	}
+	return pending
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package version
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/node"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/testlabels"
+)
+
+// Feature returns a test feature that verifies the Kubernetes Distribution
+// is running the expected version on the API server and every node
+func Feature(t *testing.T, tc *framework.TestContextType, expected string) features.Feature {
+	builder := features.New(fmt.Sprintf("kubernetes version %s", expected))
+	builder.WithLabel(testlabels.KubernetesDistribution())
+	builder.WithLabel(testlabels.Upgrade())
+
+	builder.Assess("API server runs the expected version", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		clientset, err := kubernetes.NewForConfig(c.Client().RESTConfig())
+		if err != nil {
+			t.Fatalf("unable to create clientset: %s", err)
+		}
+
+		info, err := clientset.Discovery().ServerVersion()
+		if err != nil {
+			t.Fatalf("unable to get server version: %s", err)
+		}
+
+		if err := Matches(info.GitVersion, expected); err != nil {
+			t.Errorf("API server: %s", err)
+		}
+		return ctx
+	})
+
+	builder.Assess("Nodes run the expected version", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		nodes, err := node.List(ctx, c.Client())
+		if err != nil {
+			t.Fatalf("unable to list nodes: %s", err)
+		}
+
+		for _, n := range nodes {
+			if err := Matches(n.Status.NodeInfo.KubeletVersion, expected); err != nil {
+				t.Errorf("node %s kubelet: %s", n.Name, err)
+			}
+			if !node.IsReady(&n) {
+				t.Errorf("node %s is not %s", n.Name, corev1.NodeReady)
+			}
+		}
+		return ctx
+	})
+
+	return builder.Feature()
+}
+
+// Matches returns an error unless the actual version is the same Kubernetes
+// release as the expected one. Only the major, minor and patch versions are
+// compared, as components do not report the distribution build metadata.
+func Matches(actual, expected string) error {
+	a, err := version.ParseSemantic(actual)
+	if err != nil {
+		return fmt.Errorf("unable to parse version %q: %w", actual, err)
+	}
+	e, err := version.ParseSemantic(expected)
+	if err != nil {
+		return fmt.Errorf("unable to parse version %q: %w", expected, err)
+	}
+
+	if a.Major() != e.Major() || a.Minor() != e.Minor() || a.Patch() != e.Patch() {
+		return fmt.Errorf("expected version %s, found %s", expected, actual)
+	}
+	return nil
+}
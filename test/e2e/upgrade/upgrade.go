@@ -0,0 +1,43 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"testing"
+
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/upgrade/features/cluster"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/upgrade/features/version"
+)
+
+// UpgradeTests upgrades the Kubernetes Distribution under test from
+// -upgrade-from to -upgrade-to. Workloads are deployed before the upgrade and
+// verified after it by the continuity hooks of the upgrade feature. It is only
+// run in upgrade mode.
+func UpgradeTests(t *testing.T, tc *framework.TestContextType) {
+	builder := framework.NewTestRunner()
+
+	// Pre-upgrade
+	builder.WithSerialSequence(version.Feature(t, tc, tc.UpgradeFrom))
+
+	builder.WithSerialSequence(cluster.Feature(t, tc))
+
+	// Post-upgrade
+	builder.WithSerialSequence(version.Feature(t, tc, tc.UpgradeTo))
+
+	builder.Runner().Test(t, tc)
+}
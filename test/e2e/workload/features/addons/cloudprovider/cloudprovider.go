@@ -17,10 +17,8 @@ limitations under the License.
 package cloudprovider
 
 import (
-	"context"
 	"testing"
 
-	"sigs.k8s.io/e2e-framework/pkg/envconf"
 	"sigs.k8s.io/e2e-framework/pkg/features"
 
 	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
@@ -39,10 +37,13 @@ func Feature(t *testing.T, tc *framework.TestContextType) features.Feature {
 	builder := features.New("cloud provider")
 	builder.WithLabel(testlabels.KubernetesService())
 
-	builder.Assess("DaemonSet Running", func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
-		// TODO(tvs): Add test content
-		return ctx
-	})
+	builder.Assess("Nodes are initialized", assertNodesInitialized(tc))
+
+	builder.Assess("LoadBalancer Service receives an ingress IP", createLoadBalancer)
+	builder.Assess("VirtualMachineService exists on the Kubernetes Service", assertVirtualMachineServiceExists(tc))
+	builder.Assess("Deleting the Service removes the VirtualMachineService", assertVirtualMachineServiceDeleted(tc))
+
+	builder.Teardown(deleteLoadBalancer)
 
 	return builder.Feature()
 }
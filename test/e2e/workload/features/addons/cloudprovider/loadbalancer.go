@@ -0,0 +1,234 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudprovider
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/e2e-framework/klient"
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/klient/wait/conditions"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/deployment"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/kinds"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/probe"
+)
+
+const (
+	loadBalancerName = "lb-probe"
+
+	// Labels set by the paravirtual cloud provider on the
+	// VirtualMachineService it creates for a workload cluster Service.
+	serviceNameLabel      = "run.tanzu.vmware.com/service.name"
+	serviceNamespaceLabel = "run.tanzu.vmware.com/service.namespace"
+
+	// clusterSelectorLabel is the label the VirtualMachineService selects
+	// the VirtualMachines of its cluster by.
+	clusterSelectorLabel = "capv.vmware.com/cluster.name"
+)
+
+type loadBalancerKey struct{}
+
+// loadBalancer contains the workload cluster objects backing the load
+// balancer and the supervisor object created for it.
+type loadBalancer struct {
+	deployment *appsv1.Deployment
+	service    *corev1.Service
+	vmService  *unstructured.Unstructured
+}
+
+// createLoadBalancer creates a LoadBalancer Service backed by a probe
+// Deployment and waits for the cloud provider to assign it an ingress IP. The
+// objects are created in an assessment, rather than Setup, so that Teardown
+// deletes them even if creation fails.
+func createLoadBalancer(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+	selector := map[string]string{"app": loadBalancerName}
+	lb := &loadBalancer{
+		deployment: probe.NewDeployment(loadBalancerName, c.Namespace(), 2, selector),
+		service: &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: loadBalancerName, Namespace: c.Namespace()},
+			Spec: corev1.ServiceSpec{
+				Type:     corev1.ServiceTypeLoadBalancer,
+				Selector: selector,
+				Ports: []corev1.ServicePort{{
+					Port:       80,
+					TargetPort: intstr.FromInt32(probe.Port),
+				}},
+			},
+		},
+	}
+	ctx = context.WithValue(ctx, loadBalancerKey{}, lb)
+
+	if err := c.Client().Resources().Create(ctx, lb.deployment); err != nil {
+		t.Fatalf("unable to create deployment: %s", err)
+	}
+	if err := deployment.WaitForAvailable(ctx, c.Client(), lb.deployment); err != nil {
+		t.Fatalf("deployment %s is not available: %s", lb.deployment.Name, err)
+	}
+	if err := c.Client().Resources().Create(ctx, lb.service); err != nil {
+		t.Fatalf("unable to create service: %s", err)
+	}
+
+	err := wait.For(conditions.New(c.Client().Resources()).ResourceMatch(lb.service, func(obj k8s.Object) bool {
+		for _, ingress := range obj.(*corev1.Service).Status.LoadBalancer.Ingress {
+			if ingress.IP != "" {
+				return true
+			}
+		}
+		return false
+	}),
+		wait.WithContext(ctx),
+		wait.WithTimeout(framework.NewTimeoutContext().LoadBalancer),
+		wait.WithInterval(framework.PollInterval()))
+	if err != nil {
+		t.Fatalf("service %s did not receive an ingress IP: %s", lb.service.Name, err)
+	}
+
+	return ctx
+}
+
+// assertVirtualMachineServiceExists checks that the cloud provider created a
+// VirtualMachineService and its backing Service on the Kubernetes Service
+// for the LoadBalancer Service.
+func assertVirtualMachineServiceExists(tc *framework.TestContextType) func(context.Context, *testing.T, *envconf.Config) context.Context {
+	return func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		lb := ctx.Value(loadBalancerKey{}).(*loadBalancer)
+
+		supervisor, err := tc.SupervisorClient()
+		if err != nil {
+			t.Fatalf("unable to create Kubernetes Service client: %s", err)
+		}
+
+		lb.vmService, err = findVirtualMachineService(ctx, supervisor, tc, lb.service)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var svc corev1.Service
+		if err := supervisor.Resources().Get(ctx, lb.vmService.GetName(), lb.vmService.GetNamespace(), &svc); err != nil {
+			t.Errorf("unable to get Service %s/%s backing VirtualMachineService: %s",
+				lb.vmService.GetNamespace(), lb.vmService.GetName(), err)
+		}
+
+		return ctx
+	}
+}
+
+// assertVirtualMachineServiceDeleted deletes the LoadBalancer Service and
+// waits for the VirtualMachineService and its backing Service to be removed
+// from the Kubernetes Service.
+func assertVirtualMachineServiceDeleted(tc *framework.TestContextType) func(context.Context, *testing.T, *envconf.Config) context.Context {
+	return func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		lb := ctx.Value(loadBalancerKey{}).(*loadBalancer)
+		if lb.vmService == nil {
+			t.Skip("no VirtualMachineService was found for the Service")
+		}
+
+		supervisor, err := tc.SupervisorClient()
+		if err != nil {
+			t.Fatalf("unable to create Kubernetes Service client: %s", err)
+		}
+
+		if err := c.Client().Resources().Delete(ctx, lb.service); err != nil {
+			t.Fatalf("unable to delete service %s: %s", lb.service.Name, err)
+		}
+
+		svc := &corev1.Service{
+			TypeMeta:   metav1.TypeMeta{Kind: "Service", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: lb.vmService.GetName(), Namespace: lb.vmService.GetNamespace()},
+		}
+		for _, obj := range []k8s.Object{lb.vmService, svc} {
+			err := wait.For(conditions.New(supervisor.Resources()).ResourceDeleted(obj),
+				wait.WithContext(ctx),
+				wait.WithTimeout(framework.NewTimeoutContext().LoadBalancer),
+				wait.WithInterval(framework.PollInterval()))
+			if err != nil {
+				t.Errorf("%s %s/%s was not deleted: %s",
+					obj.GetObjectKind().GroupVersionKind().Kind, obj.GetNamespace(), obj.GetName(), err)
+			}
+		}
+
+		return ctx
+	}
+}
+
+// deleteLoadBalancer removes the LoadBalancer Service and its Deployment if
+// they were not deleted by the feature.
+func deleteLoadBalancer(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+	lb, ok := ctx.Value(loadBalancerKey{}).(*loadBalancer)
+	if !ok {
+		return ctx
+	}
+
+	for _, obj := range []k8s.Object{lb.service, lb.deployment} {
+		if err := c.Client().Resources().Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+			t.Errorf("unable to delete %s: %s", obj.GetName(), err)
+		}
+	}
+
+	return ctx
+}
+
+// findVirtualMachineService returns the VirtualMachineService created on the
+// Kubernetes Service for a workload cluster Service.
+func findVirtualMachineService(ctx context.Context, c klient.Client, tc *framework.TestContextType, svc *corev1.Service) (*unstructured.Unstructured, error) {
+	vmServices := kinds.NewList(kinds.VirtualMachineService)
+	err := c.Resources(tc.ClusterNamespace).List(ctx, vmServices,
+		resources.WithLabelSelector(
+			labels.FormatLabels(map[string]string{
+				serviceNameLabel:      svc.Name,
+				serviceNamespaceLabel: svc.Namespace,
+			})))
+	if err != nil {
+		return nil, fmt.Errorf("listing VirtualMachineServices: %w", err)
+	}
+
+	// Other clusters in the namespace may have a Service of the same name,
+	// so only the VirtualMachineService selecting the VirtualMachines of the
+	// cluster under test is matched.
+	var found []*unstructured.Unstructured
+	for i := range vmServices.Items {
+		vms := &vmServices.Items[i]
+		if cluster, _, _ := unstructured.NestedString(vms.Object, "spec", "selector", clusterSelectorLabel); cluster == tc.ClusterName {
+			found = append(found, vms)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("no VirtualMachineService found in %s for service %s/%s of cluster %s",
+			tc.ClusterNamespace, svc.Namespace, svc.Name, tc.ClusterName)
+	case 1:
+		return found[0], nil
+	default:
+		return nil, fmt.Errorf("%d VirtualMachineServices found in %s for service %s/%s of cluster %s",
+			len(found), tc.ClusterNamespace, svc.Namespace, svc.Name, tc.ClusterName)
+	}
+}
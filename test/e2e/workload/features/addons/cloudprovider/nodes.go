@@ -0,0 +1,204 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudprovider
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/kinds"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/node"
+)
+
+// uninitializedTaint is set by the kubelet when it runs with an external
+// cloud provider and removed by the cloud provider once the node has been
+// initialized.
+const uninitializedTaint = "node.cloudprovider.kubernetes.io/uninitialized"
+
+// assertNodesInitialized checks that the cloud provider initialized every
+// node. If -supervisor-kubeconfig is set, the metadata of each node must also
+// match the Machine backing it on the Kubernetes Service.
+func assertNodesInitialized(tc *framework.TestContextType) func(context.Context, *testing.T, *envconf.Config) context.Context {
+	return func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		nodes, err := node.List(ctx, c.Client())
+		if err != nil {
+			t.Fatalf("unable to list nodes: %s", err)
+		}
+
+		for i := range nodes {
+			n := &nodes[i]
+
+			for _, taint := range n.Spec.Taints {
+				if taint.Key == uninitializedTaint {
+					t.Errorf("node %s still has taint %s", n.Name, uninitializedTaint)
+				}
+			}
+			if n.Spec.ProviderID == "" {
+				t.Errorf("node %s has no providerID", n.Name)
+			}
+			if node.Address(n, corev1.NodeInternalIP) == "" {
+				t.Errorf("node %s has no %s address", n.Name, corev1.NodeInternalIP)
+			}
+		}
+
+		if tc.SupervisorKubeconfig == "" {
+			t.Logf("-supervisor-kubeconfig is not set, skipping comparison of nodes with their Machines")
+			return ctx
+		}
+
+		supervisor, err := tc.SupervisorClient()
+		if err != nil {
+			t.Fatalf("unable to create Kubernetes Service client: %s", err)
+		}
+
+		cluster := kinds.New(kinds.Cluster)
+		if err := supervisor.Resources().Get(ctx, tc.ClusterName, tc.ClusterNamespace, cluster); err != nil {
+			t.Fatalf("unable to get Cluster %s/%s: %s", tc.ClusterNamespace, tc.ClusterName, err)
+		}
+
+		machines := kinds.NewList(kinds.Machine)
+		err = supervisor.Resources(tc.ClusterNamespace).List(ctx, machines,
+			resources.WithLabelSelector(labels.FormatLabels(map[string]string{kinds.ClusterNameLabel: tc.ClusterName})))
+		if err != nil {
+			t.Fatalf("unable to list Machines for cluster %s/%s: %s", tc.ClusterNamespace, tc.ClusterName, err)
+		}
+
+		byProviderID := make(map[string]*unstructured.Unstructured, len(machines.Items))
+		for i := range machines.Items {
+			m := &machines.Items[i]
+			if id, _, _ := unstructured.NestedString(m.Object, "spec", "providerID"); id != "" {
+				byProviderID[id] = m
+			}
+		}
+
+		topology := newTopology(cluster)
+		for i := range nodes {
+			n := &nodes[i]
+			if n.Spec.ProviderID == "" {
+				continue
+			}
+
+			m, ok := byProviderID[n.Spec.ProviderID]
+			if !ok {
+				t.Errorf("node %s has providerID %s, which does not match any Machine", n.Name, n.Spec.ProviderID)
+				continue
+			}
+
+			assertAddresses(t, n, m)
+			topology.assert(t, n, m)
+		}
+
+		return ctx
+	}
+}
+
+// assertAddresses checks that every IP the node reports is one of the
+// addresses of its Machine.
+func assertAddresses(t *testing.T, n *corev1.Node, m *unstructured.Unstructured) {
+	t.Helper()
+
+	addresses, _, _ := unstructured.NestedSlice(m.Object, "status", "addresses")
+	known := make(map[string]bool, len(addresses))
+	for _, a := range addresses {
+		if addr, ok := a.(map[string]interface{}); ok {
+			if s, ok := addr["address"].(string); ok {
+				known[s] = true
+			}
+		}
+	}
+
+	for _, a := range n.Status.Addresses {
+		if a.Type != corev1.NodeInternalIP && a.Type != corev1.NodeExternalIP {
+			continue
+		}
+		if !known[a.Address] {
+			t.Errorf("node %s reports %s %s, which is not an address of Machine %s",
+				n.Name, a.Type, a.Address, m.GetName())
+		}
+	}
+}
+
+// topology checks the topology labels of nodes against the failure domains
+// their Machines were placed in.
+type topology struct {
+	// regions are the regions of the failure domains of the Cluster that
+	// declare one through their attributes.
+	regions map[string]string
+
+	// seen are the regions reported by the first node checked in each
+	// failure domain, so that nodes placed in the same failure domain can be
+	// checked to agree when it does not declare a region.
+	seen map[string]string
+}
+
+func newTopology(cluster *unstructured.Unstructured) *topology {
+	t := &topology{regions: map[string]string{}, seen: map[string]string{}}
+
+	domains, _, _ := unstructured.NestedMap(cluster.Object, "status", "failureDomains")
+	for name, raw := range domains {
+		domain, _ := raw.(map[string]interface{})
+		if region, _, _ := unstructured.NestedString(domain, "attributes", "region"); region != "" {
+			t.regions[name] = region
+		}
+	}
+	return t
+}
+
+// assert checks that the node is labeled with the zone its Machine was placed
+// in and with the region of that failure domain.
+func (tp *topology) assert(t *testing.T, n *corev1.Node, m *unstructured.Unstructured) {
+	t.Helper()
+
+	zone, _, _ := unstructured.NestedString(m.Object, "spec", "failureDomain")
+	if zone == "" {
+		t.Logf("Machine %s has no failure domain, skipping topology labels of node %s", m.GetName(), n.Name)
+		return
+	}
+
+	if got := n.Labels[corev1.LabelTopologyZone]; got != zone {
+		t.Errorf("node %s has label %s=%q, expected %q from Machine %s",
+			n.Name, corev1.LabelTopologyZone, got, zone, m.GetName())
+	}
+
+	region := n.Labels[corev1.LabelTopologyRegion]
+	if region == "" {
+		t.Errorf("node %s has no %s label", n.Name, corev1.LabelTopologyRegion)
+		return
+	}
+
+	if expected, ok := tp.regions[zone]; ok {
+		if region != expected {
+			t.Errorf("node %s has label %s=%q, expected %q from failure domain %s of Machine %s",
+				n.Name, corev1.LabelTopologyRegion, region, expected, zone, m.GetName())
+		}
+		return
+	}
+
+	if expected, ok := tp.seen[zone]; ok && region != expected {
+		t.Errorf("node %s has label %s=%q, other nodes in failure domain %s have %q",
+			n.Name, corev1.LabelTopologyRegion, region, zone, expected)
+	} else if !ok {
+		tp.seen[zone] = region
+	}
+}
@@ -24,6 +24,9 @@ import (
 	"sigs.k8s.io/e2e-framework/pkg/features"
 
 	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/cni"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/daemonset"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/deployment"
 	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/testlabels"
 )
 
@@ -32,13 +35,33 @@ func Feature(t *testing.T, tc *framework.TestContextType) features.Feature {
 	builder := features.New("antrea")
 	builder.WithLabel(testlabels.KubernetesService())
 
-	builder.Assess("Deployment Running", func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
-		// TODO(tvs): Add test content
+	builder.Setup(func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		cni.SkipUnlessInstalled(ctx, t, c.Client(), cni.Antrea)
 		return ctx
 	})
 
-	builder.Assess("DaemonSet Running", func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
-		// TODO(tvs): Add test content
+	builder.Assess("Deployment Running", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		deployment.AssertAvailable(ctx, t, c.Client(), "kube-system", "antrea-controller")
+		return ctx
+	})
+
+	builder.Assess("DaemonSet Running", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		daemonset.AssertReadyOnAllNodes(ctx, t, c.Client(), "kube-system", "antrea-agent")
+		return ctx
+	})
+
+	builder.Assess("Agents report healthy", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		assertAgentsHealthy(ctx, t, c.Client())
+		return ctx
+	})
+
+	builder.Assess("Controller reports all agents connected", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		assertControllerHealthy(ctx, t, c.Client())
+		return ctx
+	})
+
+	builder.Assess("Versions match", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		assertVersions(ctx, t, c.Client(), tc.AntreaVersion)
 		return ctx
 	})
 
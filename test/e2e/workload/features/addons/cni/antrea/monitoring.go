@@ -0,0 +1,160 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package antrea
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/e2e-framework/klient"
+
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/kinds"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/node"
+)
+
+// controllerInfoName is the name of the singleton AntreaControllerInfo.
+const controllerInfoName = "antrea-controller"
+
+// heartbeatTimeout is how old the last heartbeat of an Antrea component may
+// be before it is considered stale. Antrea refreshes its monitoring CRDs
+// every minute.
+const heartbeatTimeout = 3 * time.Minute
+
+// monitoringCondition is a condition reported by the Antrea monitoring CRDs.
+type monitoringCondition struct {
+	kinds.Condition
+	LastHeartbeatTime time.Time
+}
+
+// monitoringConditions returns the conditions found in the given field of an
+// Antrea monitoring CRD.
+func monitoringConditions(u *unstructured.Unstructured, field string) []monitoringCondition {
+	raw, _, _ := unstructured.NestedSlice(u.Object, field)
+
+	conds := make([]monitoringCondition, 0, len(raw))
+	for _, r := range raw {
+		m, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		c := monitoringCondition{}
+		c.Type, _, _ = unstructured.NestedString(m, "type")
+		c.Status, _, _ = unstructured.NestedString(m, "status")
+		c.Reason, _, _ = unstructured.NestedString(m, "reason")
+		c.Message, _, _ = unstructured.NestedString(m, "message")
+		heartbeat, _, _ := unstructured.NestedString(m, "lastHeartbeatTime")
+		c.LastHeartbeatTime, _ = time.Parse(time.RFC3339, heartbeat)
+		conds = append(conds, c)
+	}
+	return conds
+}
+
+// assertHealthy checks that the health condition of an Antrea component is
+// true and has a recent heartbeat.
+func assertHealthy(t *testing.T, u *unstructured.Unstructured, field, condType string) {
+	t.Helper()
+
+	for _, cond := range monitoringConditions(u, field) {
+		if cond.Type != condType {
+			continue
+		}
+		if !cond.IsTrue() {
+			t.Errorf("%s %s condition %s is %s: %s: %s",
+				u.GetKind(), u.GetName(), cond.Type, cond.Status, cond.Reason, cond.Message)
+		}
+		if age := time.Since(cond.LastHeartbeatTime); age > heartbeatTimeout {
+			t.Errorf("%s %s last heartbeat was %s ago, more than %s",
+				u.GetKind(), u.GetName(), age.Round(time.Second), heartbeatTimeout)
+		}
+		return
+	}
+
+	t.Errorf("%s %s has no %s condition", u.GetKind(), u.GetName(), condType)
+}
+
+// assertAgentsHealthy checks that every node has an AntreaAgentInfo reporting
+// a healthy agent.
+func assertAgentsHealthy(ctx context.Context, t *testing.T, c klient.Client) {
+	t.Helper()
+
+	nodes, err := node.List(ctx, c)
+	if err != nil {
+		t.Fatalf("unable to list nodes: %s", err)
+	}
+
+	for _, n := range nodes {
+		info := kinds.New(kinds.AntreaAgentInfo)
+		if err := c.Resources().Get(ctx, n.Name, "", info); err != nil {
+			t.Errorf("unable to get AntreaAgentInfo for node %s: %s", n.Name, err)
+			continue
+		}
+		assertHealthy(t, info, "agentConditions", "AgentHealthy")
+	}
+}
+
+// assertControllerHealthy checks that the AntreaControllerInfo reports a
+// healthy controller connected to an agent on every node.
+func assertControllerHealthy(ctx context.Context, t *testing.T, c klient.Client) {
+	t.Helper()
+
+	info := kinds.New(kinds.AntreaControllerInfo)
+	if err := c.Resources().Get(ctx, controllerInfoName, "", info); err != nil {
+		t.Fatalf("unable to get AntreaControllerInfo %s: %s", controllerInfoName, err)
+	}
+	assertHealthy(t, info, "controllerConditions", "ControllerHealthy")
+
+	nodes, err := node.List(ctx, c)
+	if err != nil {
+		t.Fatalf("unable to list nodes: %s", err)
+	}
+
+	connected, _, _ := unstructured.NestedInt64(info.Object, "connectedAgentNum")
+	if connected != int64(len(nodes)) {
+		t.Errorf("Antrea controller is connected to %d agents, expected one for each of %d nodes", connected, len(nodes))
+	}
+}
+
+// assertVersions checks that the controller and every agent run the same
+// version of Antrea and, if expected is not empty, that it is the expected
+// version.
+func assertVersions(ctx context.Context, t *testing.T, c klient.Client, expected string) {
+	t.Helper()
+
+	controller := kinds.New(kinds.AntreaControllerInfo)
+	if err := c.Resources().Get(ctx, controllerInfoName, "", controller); err != nil {
+		t.Fatalf("unable to get AntreaControllerInfo %s: %s", controllerInfoName, err)
+	}
+	controllerVersion, _, _ := unstructured.NestedString(controller.Object, "version")
+
+	if expected == "" {
+		expected = controllerVersion
+	} else if controllerVersion != expected {
+		t.Errorf("Antrea controller version is %q, expected %q", controllerVersion, expected)
+	}
+
+	agents := kinds.NewList(kinds.AntreaAgentInfo)
+	if err := c.Resources().List(ctx, agents); err != nil {
+		t.Fatalf("unable to list AntreaAgentInfos: %s", err)
+	}
+	for _, a := range agents.Items {
+		if v, _, _ := unstructured.NestedString(a.Object, "version"); v != expected {
+			t.Errorf("Antrea agent on node %s version is %q, expected %q", a.GetName(), v, expected)
+		}
+	}
+}
@@ -24,6 +24,9 @@ import (
 	"sigs.k8s.io/e2e-framework/pkg/features"
 
 	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/cni"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/daemonset"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/deployment"
 	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/testlabels"
 )
 
@@ -32,13 +35,33 @@ func Feature(t *testing.T, tc *framework.TestContextType) features.Feature {
 	builder := features.New("calico")
 	builder.WithLabel(testlabels.KubernetesService())
 
-	builder.Assess("Deployment Running", func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
-		// TODO(tvs): Add test content
+	builder.Setup(func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		cni.SkipUnlessInstalled(ctx, t, c.Client(), cni.Calico)
 		return ctx
 	})
 
-	builder.Assess("DaemonSet Running", func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
-		// TODO(tvs): Add test content
+	builder.Assess("Deployment Running", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		deployment.AssertAvailable(ctx, t, c.Client(), "kube-system", "calico-kube-controllers")
+		return ctx
+	})
+
+	builder.Assess("DaemonSet Running", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		daemonset.AssertReadyOnAllNodes(ctx, t, c.Client(), "kube-system", "calico-node")
+		return ctx
+	})
+
+	builder.Assess("IPPools match cluster pod CIDR", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		assertIPPools(ctx, t, c.Client())
+		return ctx
+	})
+
+	builder.Assess("Every node has a block affinity", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		assertBlockAffinities(ctx, t, c.Client())
+		return ctx
+	})
+
+	builder.Assess("Felix ready on every node", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		assertFelixReady(ctx, t, c.Client())
 		return ctx
 	})
 
@@ -0,0 +1,186 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package calico
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/e2e-framework/klient"
+	"sigs.k8s.io/yaml"
+
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/kinds"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/node"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/pod"
+)
+
+// podCIDRs returns the pod CIDRs of the workload cluster as configured in the
+// kubeadm ClusterConfiguration.
+func podCIDRs(ctx context.Context, c klient.Client) ([]*net.IPNet, error) {
+	var cm corev1.ConfigMap
+	if err := c.Resources().Get(ctx, "kubeadm-config", "kube-system", &cm); err != nil {
+		return nil, fmt.Errorf("getting kubeadm-config: %w", err)
+	}
+
+	var config struct {
+		Networking struct {
+			PodSubnet string `json:"podSubnet"`
+		} `json:"networking"`
+	}
+	if err := yaml.Unmarshal([]byte(cm.Data["ClusterConfiguration"]), &config); err != nil {
+		return nil, fmt.Errorf("parsing ClusterConfiguration: %w", err)
+	}
+	if config.Networking.PodSubnet == "" {
+		return nil, fmt.Errorf("ClusterConfiguration has no pod subnet")
+	}
+
+	var cidrs []*net.IPNet
+	for _, s := range strings.Split(config.Networking.PodSubnet, ",") {
+		_, cidr, err := net.ParseCIDR(strings.TrimSpace(s))
+		if err != nil {
+			return nil, fmt.Errorf("parsing pod subnet %q: %w", s, err)
+		}
+		cidrs = append(cidrs, cidr)
+	}
+	return cidrs, nil
+}
+
+// contains reports whether inner is entirely within outer.
+func contains(outer, inner *net.IPNet) bool {
+	outerOnes, outerBits := outer.Mask.Size()
+	innerOnes, innerBits := inner.Mask.Size()
+	return outerBits == innerBits && outerOnes <= innerOnes && outer.Contains(inner.IP)
+}
+
+// assertIPPools checks that there is at least one enabled IPPool and that
+// every enabled IPPool is within the pod CIDRs of the cluster.
+func assertIPPools(ctx context.Context, t *testing.T, c klient.Client) {
+	t.Helper()
+
+	cidrs, err := podCIDRs(ctx, c)
+	if err != nil {
+		t.Fatalf("unable to determine cluster pod CIDRs: %s", err)
+	}
+
+	pools := kinds.NewList(kinds.CalicoIPPool)
+	if err := c.Resources().List(ctx, pools); err != nil {
+		t.Fatalf("unable to list IPPools: %s", err)
+	}
+
+	enabled := 0
+	for _, p := range pools.Items {
+		if disabled, _, _ := unstructured.NestedBool(p.Object, "spec", "disabled"); disabled {
+			continue
+		}
+		enabled++
+
+		cidr, _, _ := unstructured.NestedString(p.Object, "spec", "cidr")
+		_, poolCIDR, err := net.ParseCIDR(cidr)
+		if err != nil {
+			t.Errorf("IPPool %s has invalid CIDR %q: %s", p.GetName(), cidr, err)
+			continue
+		}
+
+		within := false
+		for _, podCIDR := range cidrs {
+			if contains(podCIDR, poolCIDR) {
+				within = true
+				break
+			}
+		}
+		if !within {
+			t.Errorf("IPPool %s CIDR %s is not within the cluster pod CIDRs %v", p.GetName(), poolCIDR, cidrs)
+		}
+	}
+
+	if enabled == 0 {
+		t.Errorf("no enabled IPPools found")
+	}
+}
+
+// assertBlockAffinities checks that every node has a confirmed affinity to at
+// least one IPAM block.
+func assertBlockAffinities(ctx context.Context, t *testing.T, c klient.Client) {
+	t.Helper()
+
+	affinities := kinds.NewList(kinds.CalicoBlockAffinity)
+	if err := c.Resources().List(ctx, affinities); err != nil {
+		t.Fatalf("unable to list BlockAffinities: %s", err)
+	}
+
+	confirmed := make(map[string]bool)
+	for _, a := range affinities.Items {
+		nodeName, _, _ := unstructured.NestedString(a.Object, "spec", "node")
+		state, _, _ := unstructured.NestedString(a.Object, "spec", "state")
+		if state == "confirmed" {
+			confirmed[nodeName] = true
+		}
+	}
+
+	nodes, err := node.List(ctx, c)
+	if err != nil {
+		t.Fatalf("unable to list nodes: %s", err)
+	}
+	for _, n := range nodes {
+		if !confirmed[n.Name] {
+			t.Errorf("node %s has no confirmed block affinity", n.Name)
+		}
+	}
+}
+
+// assertFelixReady checks that Felix reports ready in the calico-node pod on
+// every node.
+func assertFelixReady(ctx context.Context, t *testing.T, c klient.Client) {
+	t.Helper()
+
+	pods, err := pod.List(ctx, c, "kube-system", &metav1.LabelSelector{
+		MatchLabels: map[string]string{"k8s-app": "calico-node"},
+	})
+	if err != nil {
+		t.Fatalf("unable to list calico-node pods: %s", err)
+	}
+
+	byNode := make(map[string]*corev1.Pod, len(pods))
+	for i := range pods {
+		byNode[pods[i].Spec.NodeName] = &pods[i]
+	}
+
+	nodes, err := node.List(ctx, c)
+	if err != nil {
+		t.Fatalf("unable to list nodes: %s", err)
+	}
+	for _, n := range nodes {
+		p, ok := byNode[n.Name]
+		if !ok {
+			t.Errorf("node %s has no calico-node pod", n.Name)
+			continue
+		}
+
+		var stdout, stderr bytes.Buffer
+		cmd := []string{"/bin/calico-node", "-felix-ready"}
+		if err := c.Resources().ExecInPod(ctx, p.Namespace, p.Name, "calico-node", cmd, &stdout, &stderr); err != nil {
+			t.Errorf("felix is not ready on node %s: %s: %s", n.Name, strings.TrimSpace(stderr.String()), err)
+		}
+	}
+}
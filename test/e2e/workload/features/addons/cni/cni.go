@@ -24,12 +24,18 @@ import (
 	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
 	"github.com/tvs/kubernetes-service-tests/test/e2e/workload/features/addons/cni/antrea"
 	"github.com/tvs/kubernetes-service-tests/test/e2e/workload/features/addons/cni/calico"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/workload/features/addons/cni/connectivity"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/workload/features/addons/cni/networkpolicy"
 )
 
-// Features returns a list of CNI test features to be run in a given context
+// Features returns a list of CNI test features to be run in a given context.
+// Only the feature for the installed CNI runs, the others are skipped.
+// Features that are not specific to a CNI always run.
 func Features(t *testing.T, tc *framework.TestContextType) []features.Feature {
 	return []features.Feature{
 		antrea.Feature(t, tc),
 		calico.Feature(t, tc),
+		connectivity.Feature(t, tc),
+		networkpolicy.Feature(t, tc),
 	}
 }
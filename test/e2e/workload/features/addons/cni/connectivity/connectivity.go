@@ -0,0 +1,213 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connectivity
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/e2e-framework/klient"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/node"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/pod"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/probe"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/testlabels"
+)
+
+const (
+	appLabel    = "app"
+	appName     = "connectivity-probe"
+	servicePort = 80
+)
+
+type probesKey struct{}
+
+// probes are the pods and Service deployed to check connectivity. Two pods
+// are deployed to every schedulable node so that connectivity can be checked
+// both within and across nodes.
+type probes struct {
+	nodes   []corev1.Node
+	pods    map[string][2]*corev1.Pod
+	service *corev1.Service
+
+	// created are the probe pods created so far, which may be fewer than
+	// in pods if Setup fails.
+	created []*corev1.Pod
+}
+
+func (p *probes) nodeNames() []string {
+	names := make([]string, 0, len(p.nodes))
+	for _, n := range p.nodes {
+		names = append(names, n.Name)
+	}
+	return names
+}
+
+// Feature returns a test feature that checks pod network connectivity
+// independently of the installed CNI
+func Feature(t *testing.T, tc *framework.TestContextType) features.Feature {
+	builder := features.New("pod connectivity")
+	builder.WithLabel(testlabels.WorkloadCluster())
+	builder.WithLabel(testlabels.Conformance())
+
+	builder.Setup(func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		nodes, err := node.ListSchedulable(ctx, c.Client())
+		if err != nil {
+			t.Fatalf("unable to list schedulable nodes: %s", err)
+		}
+		if len(nodes) == 0 {
+			t.Fatal("no schedulable nodes found")
+		}
+
+		p := &probes{
+			nodes: nodes,
+			pods:  make(map[string][2]*corev1.Pod, len(nodes)),
+		}
+
+		// Teardown is skipped when Setup fails, so the probes created so
+		// far are deleted before failing to keep them out of the shared
+		// namespace.
+		fail := func(format string, args ...any) {
+			t.Helper()
+			p.delete(ctx, t, c.Client())
+			t.Fatalf(format, args...)
+		}
+
+		for i, n := range nodes {
+			var pair [2]*corev1.Pod
+			for j := range pair {
+				pair[j] = probe.NewPod(fmt.Sprintf("%s-%d-%d", appName, i, j), c.Namespace(), n.Name,
+					map[string]string{appLabel: appName})
+				if err := c.Client().Resources().Create(ctx, pair[j]); err != nil {
+					fail("unable to create probe pod on node %s: %s", n.Name, err)
+				}
+				p.created = append(p.created, pair[j])
+			}
+			p.pods[n.Name] = pair
+		}
+
+		p.service = &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: appName, Namespace: c.Namespace()},
+			Spec: corev1.ServiceSpec{
+				Selector: map[string]string{appLabel: appName},
+				Ports: []corev1.ServicePort{{
+					Port:       servicePort,
+					TargetPort: intstr.FromInt32(probe.Port),
+				}},
+			},
+		}
+		if err := c.Client().Resources().Create(ctx, p.service); err != nil {
+			p.service = nil
+			fail("unable to create probe service: %s", err)
+		}
+
+		if err := pod.WaitForReady(ctx, c.Client(), p.created...); err != nil {
+			fail("%s", err)
+		}
+
+		return context.WithValue(ctx, probesKey{}, p)
+	})
+
+	builder.Assess("Pod to Pod", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		p := ctx.Value(probesKey{}).(*probes)
+
+		// Connectivity within a node is checked between the two probes on the
+		// node, across nodes it is checked between the first probe of each.
+		m := newMatrix(p.nodeNames(), p.nodeNames())
+		m.run(func(src, dst string) error {
+			target := p.pods[dst][0]
+			if src == dst {
+				target = p.pods[dst][1]
+			}
+			return probe.Connect(ctx, c.Client(), p.pods[src][0], target.Status.PodIP, probe.Port)
+		})
+		m.report(t)
+
+		return ctx
+	})
+
+	builder.Assess("Pod to ClusterIP Service", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		p := ctx.Value(probesKey{}).(*probes)
+
+		m := newMatrix(p.nodeNames(), []string{p.service.Name})
+		m.run(func(src, _ string) error {
+			return probe.Connect(ctx, c.Client(), p.pods[src][0], p.service.Spec.ClusterIP, servicePort)
+		})
+		m.report(t)
+
+		return ctx
+	})
+
+	builder.Assess("Pod to Node", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		p := ctx.Value(probesKey{}).(*probes)
+
+		nodes := make(map[string]*corev1.Node, len(p.nodes))
+		for i := range p.nodes {
+			nodes[p.nodes[i].Name] = &p.nodes[i]
+		}
+
+		// Nodes are reached through the kubelet, which listens on every node
+		// regardless of the workloads running on it.
+		m := newMatrix(p.nodeNames(), p.nodeNames())
+		m.run(func(src, dst string) error {
+			n := nodes[dst]
+			ip := node.Address(n, corev1.NodeInternalIP)
+			if ip == "" {
+				return fmt.Errorf("node %s has no internal IP", n.Name)
+			}
+			return probe.Connect(ctx, c.Client(), p.pods[src][0], ip, n.Status.DaemonEndpoints.KubeletEndpoint.Port)
+		})
+		m.report(t)
+
+		return ctx
+	})
+
+	builder.Teardown(func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		p, ok := ctx.Value(probesKey{}).(*probes)
+		if !ok {
+			return ctx
+		}
+
+		p.delete(ctx, t, c.Client())
+		return ctx
+	})
+
+	return builder.Feature()
+}
+
+// delete deletes the probe pods and Service that were created.
+func (p *probes) delete(ctx context.Context, t *testing.T, c klient.Client) {
+	t.Helper()
+
+	for _, probePod := range p.created {
+		if err := c.Resources().Delete(ctx, probePod); err != nil {
+			t.Errorf("unable to delete probe pod %s: %s", probePod.Name, err)
+		}
+	}
+	if p.service != nil {
+		if err := c.Resources().Delete(ctx, p.service); err != nil {
+			t.Errorf("unable to delete probe service: %s", err)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connectivity
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"text/tabwriter"
+)
+
+// matrix records the results of connectivity checks from a set of sources to
+// a set of destinations.
+type matrix struct {
+	sources      []string
+	destinations []string
+
+	mu      sync.Mutex
+	results map[string]map[string]error
+}
+
+func newMatrix(sources, destinations []string) *matrix {
+	results := make(map[string]map[string]error, len(sources))
+	for _, s := range sources {
+		results[s] = make(map[string]error, len(destinations))
+	}
+
+	return &matrix{
+		sources:      sources,
+		destinations: destinations,
+		results:      results,
+	}
+}
+
+// run checks connectivity from every source to every destination in
+// parallel, recording the result of each check.
+func (m *matrix) run(check func(src, dst string) error) {
+	var wg sync.WaitGroup
+	for _, src := range m.sources {
+		for _, dst := range m.destinations {
+			wg.Add(1)
+			go func(src, dst string) {
+				defer wg.Done()
+				err := check(src, dst)
+
+				m.mu.Lock()
+				defer m.mu.Unlock()
+				m.results[src][dst] = err
+			}(src, dst)
+		}
+	}
+	wg.Wait()
+}
+
+// String renders the matrix as a table with a row per source and a column
+// per destination.
+func (m *matrix) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "FROM \\ TO\t%s\t\n", strings.Join(m.destinations, "\t"))
+	for _, src := range m.sources {
+		cells := make([]string, 0, len(m.destinations))
+		for _, dst := range m.destinations {
+			err, ok := m.results[src][dst]
+			switch {
+			case !ok:
+				cells = append(cells, "-")
+			case err != nil:
+				cells = append(cells, "FAIL")
+			default:
+				cells = append(cells, "ok")
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t\n", src, strings.Join(cells, "\t"))
+	}
+
+	w.Flush()
+	return b.String()
+}
+
+// report logs the matrix and fails the test with each failed check.
+func (m *matrix) report(t *testing.T) {
+	t.Helper()
+
+	t.Logf("connectivity matrix:\n%s", m)
+	for _, src := range m.sources {
+		for _, dst := range m.destinations {
+			if err := m.results[src][dst]; err != nil {
+				t.Errorf("%s -> %s: %s", src, dst, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,350 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkpolicy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"text/tabwriter"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/pod"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/probe"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/testlabels"
+)
+
+const (
+	roleLabel  = "role"
+	roleServer = "server"
+	roleClient = "client"
+	roleOther  = "other"
+)
+
+// Clients from which connections to the server are made. The server and the
+// first two clients run in the server namespace, the last in a peer
+// namespace.
+const (
+	localClient = "local/client"
+	localOther  = "local/other"
+	peerClient  = "peer/client"
+)
+
+var clients = []string{localClient, localOther, peerClient}
+
+// baseline is the connectivity expected while no policies are enforced.
+var baseline = map[string]bool{localClient: true, localOther: true, peerClient: true}
+
+type envKey struct{}
+
+// env contains the namespaces and pods that policies are applied to.
+type env struct {
+	local, peer *corev1.Namespace
+	server      *corev1.Pod
+	clients     map[string]*corev1.Pod
+}
+
+// policyCase is a set of policies applied to the server namespace and
+// whether each client is expected to reach the server while they are
+// enforced.
+type policyCase struct {
+	name     string
+	policies func(e *env) []*networkingv1.NetworkPolicy
+	allowed  map[string]bool
+}
+
+var cases = []policyCase{
+	{
+		name:     "No policy",
+		policies: func(*env) []*networkingv1.NetworkPolicy { return nil },
+		allowed:  baseline,
+	},
+	{
+		name: "Default deny ingress",
+		policies: func(e *env) []*networkingv1.NetworkPolicy {
+			return []*networkingv1.NetworkPolicy{denyAll(e.local.Name, networkingv1.PolicyTypeIngress)}
+		},
+		allowed: map[string]bool{localClient: false, localOther: false, peerClient: false},
+	},
+	{
+		name: "Pod selector ingress",
+		policies: func(e *env) []*networkingv1.NetworkPolicy {
+			return []*networkingv1.NetworkPolicy{
+				denyAll(e.local.Name, networkingv1.PolicyTypeIngress),
+				allowIngress(e.local.Name, "allow-client-pods", networkingv1.NetworkPolicyPeer{
+					PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{roleLabel: roleClient}},
+				}),
+			}
+		},
+		allowed: map[string]bool{localClient: true, localOther: false, peerClient: false},
+	},
+	{
+		name: "Namespace selector ingress",
+		policies: func(e *env) []*networkingv1.NetworkPolicy {
+			return []*networkingv1.NetworkPolicy{
+				denyAll(e.local.Name, networkingv1.PolicyTypeIngress),
+				allowIngress(e.local.Name, "allow-peer-namespace", networkingv1.NetworkPolicyPeer{
+					NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{corev1.LabelMetadataName: e.peer.Name}},
+				}),
+			}
+		},
+		allowed: map[string]bool{localClient: false, localOther: false, peerClient: true},
+	},
+	{
+		name: "Default deny egress",
+		policies: func(e *env) []*networkingv1.NetworkPolicy {
+			return []*networkingv1.NetworkPolicy{denyAll(e.local.Name, networkingv1.PolicyTypeEgress)}
+		},
+		allowed: map[string]bool{localClient: false, localOther: false, peerClient: true},
+	},
+	{
+		name: "Pod selector egress",
+		policies: func(e *env) []*networkingv1.NetworkPolicy {
+			return []*networkingv1.NetworkPolicy{
+				denyAll(e.local.Name, networkingv1.PolicyTypeEgress),
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "allow-client-egress", Namespace: e.local.Name},
+					Spec: networkingv1.NetworkPolicySpec{
+						PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{roleLabel: roleClient}},
+						PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+						Egress: []networkingv1.NetworkPolicyEgressRule{{
+							To: []networkingv1.NetworkPolicyPeer{{
+								PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{roleLabel: roleServer}},
+							}},
+						}},
+					},
+				},
+			}
+		},
+		allowed: map[string]bool{localClient: true, localOther: false, peerClient: true},
+	},
+}
+
+// Feature returns a test feature that checks NetworkPolicies are enforced by
+// the installed CNI
+func Feature(t *testing.T, tc *framework.TestContextType) features.Feature {
+	builder := features.New("network policy")
+	builder.WithLabel(testlabels.WorkloadCluster())
+	builder.WithLabel(testlabels.Conformance())
+
+	builder.Setup(func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		e := &env{
+			local:   &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: envconf.RandomName("netpol-local", 20)}},
+			peer:    &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: envconf.RandomName("netpol-peer", 20)}},
+			clients: make(map[string]*corev1.Pod, len(clients)),
+		}
+		ctx = context.WithValue(ctx, envKey{}, e)
+
+		for _, ns := range []*corev1.Namespace{e.local, e.peer} {
+			if err := c.Client().Resources().Create(ctx, ns); err != nil {
+				t.Fatalf("unable to create namespace %s: %s", ns.Name, err)
+			}
+		}
+
+		e.server = probe.NewPod(roleServer, e.local.Name, "", map[string]string{roleLabel: roleServer})
+		e.clients[localClient] = probe.NewPod(roleClient, e.local.Name, "", map[string]string{roleLabel: roleClient})
+		e.clients[localOther] = probe.NewPod(roleOther, e.local.Name, "", map[string]string{roleLabel: roleOther})
+		e.clients[peerClient] = probe.NewPod(roleClient, e.peer.Name, "", map[string]string{roleLabel: roleClient})
+
+		pods := []*corev1.Pod{e.server}
+		for _, name := range clients {
+			pods = append(pods, e.clients[name])
+		}
+		for _, p := range pods {
+			if err := c.Client().Resources().Create(ctx, p); err != nil {
+				t.Fatalf("unable to create pod %s/%s: %s", p.Namespace, p.Name, err)
+			}
+		}
+		if err := pod.WaitForReady(ctx, c.Client(), pods...); err != nil {
+			t.Fatal(err)
+		}
+
+		return ctx
+	})
+
+	for _, pc := range cases {
+		builder.Assess(pc.name, func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+			e := ctx.Value(envKey{}).(*env)
+
+			var created []*networkingv1.NetworkPolicy
+			defer func() {
+				for _, np := range created {
+					if err := c.Client().Resources().Delete(ctx, np); err != nil {
+						t.Errorf("unable to delete network policy %s/%s: %s", np.Namespace, np.Name, err)
+					}
+				}
+				if len(created) == 0 {
+					return
+				}
+
+				// The next case must not observe the rules of this one while
+				// the CNI is still removing them.
+				if actual, err := waitForConnectivity(ctx, c, e, baseline); err != nil {
+					t.Errorf("connectivity did not return to the no policy baseline after deleting the policies:\n%s",
+						table(baseline, actual))
+				}
+			}()
+
+			for _, np := range pc.policies(e) {
+				if err := c.Client().Resources().Create(ctx, np); err != nil {
+					t.Fatalf("unable to create network policy %s/%s: %s", np.Namespace, np.Name, err)
+				}
+				created = append(created, np)
+			}
+
+			actual, err := waitForConnectivity(ctx, c, e, pc.allowed)
+
+			t.Logf("network policy results:\n%s", table(pc.allowed, actual))
+			if err != nil {
+				for _, name := range clients {
+					if pc.allowed[name] != (actual[name] == nil) {
+						t.Errorf("%s -> server: expected %s, got %s", name, verdict(pc.allowed[name]), result(actual[name]))
+					}
+				}
+			}
+
+			return ctx
+		})
+	}
+
+	builder.Teardown(func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		e, ok := ctx.Value(envKey{}).(*env)
+		if !ok {
+			return ctx
+		}
+
+		for _, ns := range []*corev1.Namespace{e.local, e.peer} {
+			if err := c.Client().Resources().Delete(ctx, ns); err != nil {
+				t.Errorf("unable to delete namespace %s: %s", ns.Name, err)
+			}
+		}
+
+		return ctx
+	})
+
+	return builder.Feature()
+}
+
+// waitForConnectivity checks the connections from every client until they
+// match the expected verdicts or the timeout passes, as policies take some time
+// to be programmed by the CNI. The last results are returned.
+func waitForConnectivity(ctx context.Context, c *envconf.Config, e *env, allowed map[string]bool) (map[string]error, error) {
+	var actual map[string]error
+	err := wait.For(func(ctx context.Context) (bool, error) {
+		actual = connect(ctx, c, e)
+		for _, name := range clients {
+			if allowed[name] != (actual[name] == nil) {
+				return false, nil
+			}
+		}
+		return true, nil
+	},
+		wait.WithContext(ctx),
+		wait.WithTimeout(framework.NewTimeoutContext().PodStart),
+		wait.WithInterval(framework.PollInterval()),
+		wait.WithImmediate())
+	return actual, err
+}
+
+// connect attempts a connection from every client to the server in parallel
+// and returns the result for each.
+func connect(ctx context.Context, c *envconf.Config, e *env) map[string]error {
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]error, len(clients))
+	)
+
+	for _, name := range clients {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			err := probe.Connect(ctx, c.Client(), e.clients[name], e.server.Status.PodIP, probe.Port)
+
+			mu.Lock()
+			defer mu.Unlock()
+			results[name] = err
+		}(name)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// table renders the expected and actual result for each client.
+func table(allowed map[string]bool, actual map[string]error) string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "FROM\tEXPECTED\tACTUAL\t")
+	for _, name := range clients {
+		fmt.Fprintf(w, "%s\t%s\t%s\t\n", name, verdict(allowed[name]), result(actual[name]))
+	}
+
+	w.Flush()
+	return b.String()
+}
+
+func verdict(allowed bool) string {
+	if allowed {
+		return "allowed"
+	}
+	return "blocked"
+}
+
+func result(err error) string {
+	return verdict(err == nil)
+}
+
+// denyAll returns a policy that selects every pod in the namespace without
+// allowing any traffic of the given type.
+func denyAll(namespace string, policyType networkingv1.PolicyType) *networkingv1.NetworkPolicy {
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "default-deny-" + strings.ToLower(string(policyType)),
+			Namespace: namespace,
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{},
+			PolicyTypes: []networkingv1.PolicyType{policyType},
+		},
+	}
+}
+
+// allowIngress returns a policy that allows ingress to the server from the
+// given peer.
+func allowIngress(namespace, name string, from networkingv1.NetworkPolicyPeer) *networkingv1.NetworkPolicy {
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{roleLabel: roleServer}},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{{
+				From: []networkingv1.NetworkPolicyPeer{from},
+			}},
+		},
+	}
+}
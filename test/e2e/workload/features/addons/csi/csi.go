@@ -0,0 +1,109 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csi
+
+import (
+	"context"
+	"testing"
+
+	storagev1 "k8s.io/api/storage/v1"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/daemonset"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/deployment"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/node"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/testlabels"
+)
+
+const (
+	// Driver is the name of the paravirtual vSphere CSI driver.
+	Driver = "csi.vsphere.vmware.com"
+
+	namespace = "vmware-system-csi"
+)
+
+// Features returns a list of CSI test features to be run in a given context
+func Features(t *testing.T, tc *framework.TestContextType) []features.Feature {
+	return []features.Feature{
+		Feature(t, tc),
+	}
+}
+
+// SerialFeatures returns a list of slow CSI test features, which must be run
+// in a serial sequence
+func SerialFeatures(t *testing.T, tc *framework.TestContextType) []features.Feature {
+	return []features.Feature{
+		VolumeFeature(t, tc),
+	}
+}
+
+// Feature returns a test feature for the CSI driver
+func Feature(t *testing.T, tc *framework.TestContextType) features.Feature {
+	builder := features.New("csi")
+	builder.WithLabel(testlabels.WorkloadCluster())
+
+	builder.Assess("CSIDriver is registered", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		var driver storagev1.CSIDriver
+		if err := c.Client().Resources().Get(ctx, Driver, "", &driver); err != nil {
+			t.Errorf("unable to get CSIDriver %s: %s", Driver, err)
+		}
+		return ctx
+	})
+
+	builder.Assess("Deployment Running", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		deployment.AssertAvailable(ctx, t, c.Client(), namespace, "vsphere-csi-controller")
+		return ctx
+	})
+
+	builder.Assess("DaemonSet Running", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		daemonset.AssertReadyOnAllNodes(ctx, t, c.Client(), namespace, "vsphere-csi-node")
+		return ctx
+	})
+
+	builder.Assess("Node plugin is registered on every node", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		nodes, err := node.List(ctx, c.Client())
+		if err != nil {
+			t.Fatalf("unable to list nodes: %s", err)
+		}
+
+		for _, n := range nodes {
+			var csiNode storagev1.CSINode
+			if err := c.Client().Resources().Get(ctx, n.Name, "", &csiNode); err != nil {
+				t.Errorf("unable to get CSINode %s: %s", n.Name, err)
+				continue
+			}
+			if !hasDriver(&csiNode, Driver) {
+				t.Errorf("CSINode %s does not have driver %s registered", n.Name, Driver)
+			}
+		}
+
+		return ctx
+	})
+
+	return builder.Feature()
+}
+
+func hasDriver(n *storagev1.CSINode, name string) bool {
+	for _, d := range n.Spec.Drivers {
+		if d.Name == name {
+			return true
+		}
+	}
+	return false
+}
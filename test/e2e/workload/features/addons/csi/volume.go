@@ -0,0 +1,320 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csi
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/e2e-framework/klient"
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/klient/wait/conditions"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/pod"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/testlabels"
+)
+
+const (
+	// image is run by the pods that mount the volumes under test.
+	image = "registry.k8s.io/e2e-test-images/busybox:1.36.1-1"
+
+	container = "volume"
+	mountPath = "/mnt/volume"
+	dataFile  = mountPath + "/data"
+)
+
+var (
+	initialSize  = resource.MustParse("1Gi")
+	expandedSize = resource.MustParse("2Gi")
+)
+
+type volumesKey struct{}
+
+// volume is a claim provisioned from a StorageClass and the pod mounting it.
+type volume struct {
+	storageClass *storagev1.StorageClass
+	claim        *corev1.PersistentVolumeClaim
+	pod          *corev1.Pod
+}
+
+// VolumeFeature returns a test feature that provisions, uses, expands and
+// deletes a volume from every StorageClass synced from the Kubernetes
+// Service.
+func VolumeFeature(t *testing.T, tc *framework.TestContextType) features.Feature {
+	builder := features.New("csi volume lifecycle")
+	builder.WithLabel(testlabels.WorkloadCluster())
+	builder.WithLabel(testlabels.Slow())
+
+	builder.Setup(func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		var classes storagev1.StorageClassList
+		if err := c.Client().Resources().List(ctx, &classes); err != nil {
+			t.Fatalf("unable to list storage classes: %s", err)
+		}
+
+		var volumes []*volume
+		for i := range classes.Items {
+			sc := &classes.Items[i]
+			if sc.Provisioner != Driver {
+				continue
+			}
+
+			// The volume is recorded before its objects are created so that
+			// a partially created one is deleted too.
+			v := newVolume(sc, c.Namespace())
+			volumes = append(volumes, v)
+			for _, obj := range []k8s.Object{v.claim, v.pod} {
+				if err := c.Client().Resources().Create(ctx, obj); err != nil {
+					// Teardown is skipped when Setup fails, so the volumes
+					// created so far are deleted here instead.
+					deleteVolumes(ctx, t, c.Client(), volumes)
+					t.Fatalf("unable to create %s for storage class %s: %s", obj.GetName(), sc.Name, err)
+				}
+			}
+		}
+		if len(volumes) == 0 {
+			t.Skipf("no storage classes provisioned by %s found", Driver)
+		}
+
+		return context.WithValue(ctx, volumesKey{}, volumes)
+	})
+
+	builder.Assess("PersistentVolumeClaims are bound", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		for _, v := range ctx.Value(volumesKey{}).([]*volume) {
+			err := wait.For(conditions.New(c.Client().Resources()).ResourceMatch(v.claim, func(obj k8s.Object) bool {
+				return obj.(*corev1.PersistentVolumeClaim).Status.Phase == corev1.ClaimBound
+			}),
+				wait.WithContext(ctx),
+				wait.WithTimeout(framework.NewTimeoutContext().ClaimProvision),
+				wait.WithInterval(framework.PollInterval()))
+			if err != nil {
+				t.Errorf("storage class %s: claim %s is %s: %s", v.storageClass.Name, v.claim.Name, v.claim.Status.Phase, err)
+				continue
+			}
+
+			if err := pod.WaitForReady(ctx, c.Client(), v.pod); err != nil {
+				t.Errorf("storage class %s: %s", v.storageClass.Name, err)
+			}
+		}
+
+		return ctx
+	})
+
+	builder.Assess("Data is written and read back", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		for _, v := range ctx.Value(volumesKey{}).([]*volume) {
+			data := fmt.Sprintf("written to %s", v.claim.Name)
+			if _, err := execInPod(ctx, c.Client(), v.pod, "sh", "-c", fmt.Sprintf("echo %q > %s && sync", data, dataFile)); err != nil {
+				t.Errorf("storage class %s: unable to write to volume: %s", v.storageClass.Name, err)
+				continue
+			}
+
+			read, err := execInPod(ctx, c.Client(), v.pod, "cat", dataFile)
+			switch {
+			case err != nil:
+				t.Errorf("storage class %s: unable to read from volume: %s", v.storageClass.Name, err)
+			case strings.TrimSpace(read) != data:
+				t.Errorf("storage class %s: read %q from volume, expected %q", v.storageClass.Name, read, data)
+			}
+		}
+
+		return ctx
+	})
+
+	builder.Assess("Volumes are expanded", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		for _, v := range ctx.Value(volumesKey{}).([]*volume) {
+			if v.storageClass.AllowVolumeExpansion == nil || !*v.storageClass.AllowVolumeExpansion {
+				t.Logf("storage class %s does not allow volume expansion, skipping", v.storageClass.Name)
+				continue
+			}
+
+			if err := c.Client().Resources().Get(ctx, v.claim.Name, v.claim.Namespace, v.claim); err != nil {
+				t.Errorf("storage class %s: unable to get claim %s: %s", v.storageClass.Name, v.claim.Name, err)
+				continue
+			}
+			v.claim.Spec.Resources.Requests[corev1.ResourceStorage] = expandedSize
+			if err := c.Client().Resources().Update(ctx, v.claim); err != nil {
+				t.Errorf("storage class %s: unable to expand claim %s: %s", v.storageClass.Name, v.claim.Name, err)
+				continue
+			}
+
+			err := wait.For(conditions.New(c.Client().Resources()).ResourceMatch(v.claim, func(obj k8s.Object) bool {
+				capacity := obj.(*corev1.PersistentVolumeClaim).Status.Capacity[corev1.ResourceStorage]
+				return capacity.Cmp(expandedSize) >= 0
+			}),
+				wait.WithContext(ctx),
+				wait.WithTimeout(framework.NewTimeoutContext().ClaimProvision),
+				wait.WithInterval(framework.PollInterval()))
+			if err != nil {
+				capacity := v.claim.Status.Capacity[corev1.ResourceStorage]
+				t.Errorf("storage class %s: claim %s has capacity %s, expected %s: %s",
+					v.storageClass.Name, v.claim.Name, capacity.String(), expandedSize.String(), err)
+			}
+		}
+
+		return ctx
+	})
+
+	builder.Assess("Deleting the claim reclaims the volume", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		supervisor, err := tc.SupervisorClient()
+		if err != nil {
+			t.Fatalf("unable to create Kubernetes Service client: %s", err)
+		}
+
+		for _, v := range ctx.Value(volumesKey{}).([]*volume) {
+			if err := c.Client().Resources().Get(ctx, v.claim.Name, v.claim.Namespace, v.claim); err != nil {
+				t.Errorf("storage class %s: unable to get claim %s: %s", v.storageClass.Name, v.claim.Name, err)
+				continue
+			}
+			if v.claim.Spec.VolumeName == "" {
+				t.Errorf("storage class %s: claim %s is not bound to a volume", v.storageClass.Name, v.claim.Name)
+				continue
+			}
+
+			var pv corev1.PersistentVolume
+			if err := c.Client().Resources().Get(ctx, v.claim.Spec.VolumeName, "", &pv); err != nil {
+				t.Errorf("storage class %s: unable to get volume %s: %s", v.storageClass.Name, v.claim.Spec.VolumeName, err)
+				continue
+			}
+
+			for _, obj := range []k8s.Object{v.pod, v.claim} {
+				if err := c.Client().Resources().Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+					t.Errorf("storage class %s: unable to delete %s: %s", v.storageClass.Name, obj.GetName(), err)
+				}
+			}
+
+			if pv.Spec.PersistentVolumeReclaimPolicy != corev1.PersistentVolumeReclaimDelete {
+				t.Logf("storage class %s: volume %s has reclaim policy %s, skipping",
+					v.storageClass.Name, pv.Name, pv.Spec.PersistentVolumeReclaimPolicy)
+				continue
+			}
+
+			waitForDeleted(ctx, t, c.Client(), &pv)
+
+			// The paravirtual driver backs every volume with a claim in the
+			// cluster namespace on the Kubernetes Service named after the
+			// volume handle.
+			if pv.Spec.CSI == nil {
+				t.Errorf("storage class %s: volume %s is not a CSI volume", v.storageClass.Name, pv.Name)
+				continue
+			}
+			supervisorClaim := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: pv.Spec.CSI.VolumeHandle, Namespace: tc.ClusterNamespace},
+			}
+			waitForDeleted(ctx, t, supervisor, supervisorClaim)
+		}
+
+		return ctx
+	})
+
+	builder.Teardown(func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		volumes, ok := ctx.Value(volumesKey{}).([]*volume)
+		if !ok {
+			return ctx
+		}
+
+		deleteVolumes(ctx, t, c.Client(), volumes)
+		return ctx
+	})
+
+	return builder.Feature()
+}
+
+// deleteVolumes deletes the pod and claim of each volume, ignoring those that
+// were never created or are already deleted.
+func deleteVolumes(ctx context.Context, t *testing.T, c klient.Client, volumes []*volume) {
+	t.Helper()
+
+	for _, v := range volumes {
+		for _, obj := range []k8s.Object{v.pod, v.claim} {
+			if err := c.Resources().Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+				t.Errorf("unable to delete %s: %s", obj.GetName(), err)
+			}
+		}
+	}
+}
+
+// newVolume returns a claim from the StorageClass and a pod mounting it.
+func newVolume(sc *storagev1.StorageClass, namespace string) *volume {
+	name := envconf.RandomName("csi", 12)
+
+	claim := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: &sc.Name,
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: initialSize},
+			},
+		},
+	}
+
+	p := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:         container,
+				Image:        image,
+				Command:      []string{"sh", "-c", "trap exit TERM; while true; do sleep 1; done"},
+				VolumeMounts: []corev1.VolumeMount{{Name: container, MountPath: mountPath}},
+			}},
+			Volumes: []corev1.Volume{{
+				Name: container,
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: name},
+				},
+			}},
+		},
+	}
+
+	return &volume{storageClass: sc, claim: claim, pod: p}
+}
+
+// execInPod runs the command in the volume pod and returns its output.
+func execInPod(ctx context.Context, c klient.Client, p *corev1.Pod, cmd ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	if err := c.Resources().ExecInPod(ctx, p.Namespace, p.Name, container, cmd, &stdout, &stderr); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %s", msg, err)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
+
+func waitForDeleted(ctx context.Context, t *testing.T, c klient.Client, obj k8s.Object) {
+	t.Helper()
+
+	err := wait.For(conditions.New(c.Resources()).ResourceDeleted(obj),
+		wait.WithContext(ctx),
+		wait.WithTimeout(framework.NewTimeoutContext().PVDelete),
+		wait.WithInterval(framework.PollInterval()),
+		wait.WithImmediate())
+	if err != nil {
+		t.Errorf("%T %s was not deleted: %s", obj, obj.GetName(), err)
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dns
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/e2e-framework/klient"
+)
+
+const (
+	// image is run by the pods that DNS queries are made from.
+	image = "registry.k8s.io/e2e-test-images/jessie-dnsutils:1.7"
+
+	container = "dnsutils"
+)
+
+// record is a single record from the answer section of a DNS response.
+type record struct {
+	Type  string
+	Value string
+}
+
+// answer is the result of a DNS query.
+type answer struct {
+	Records   []record
+	QueryTime time.Duration
+}
+
+// values returns the values of the records of the given type.
+func (a *answer) values(recordType string) []string {
+	var values []string
+	for _, r := range a.Records {
+		if r.Type == recordType {
+			values = append(values, r.Value)
+		}
+	}
+	return values
+}
+
+// newClientPod returns a pod from which DNS queries are made, bound to the
+// node and tolerating all of its taints.
+func newClientPod(name, namespace, node string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: corev1.PodSpec{
+			NodeName:    node,
+			Tolerations: []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+			Containers: []corev1.Container{{
+				Name:    container,
+				Image:   image,
+				Command: []string{"sh", "-c", "trap exit TERM; while true; do sleep 1; done"},
+			}},
+		},
+	}
+}
+
+// lookup queries the cluster DNS for records of the given type from within
+// the client pod p.
+func lookup(ctx context.Context, c klient.Client, p *corev1.Pod, name, recordType string) (*answer, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := []string{"dig", "+noall", "+answer", "+stats", "+time=5", "+tries=1", name, recordType}
+
+	if err := c.Resources().ExecInPod(ctx, p.Namespace, p.Name, container, cmd, &stdout, &stderr); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %s", msg, err)
+		}
+		return nil, err
+	}
+	return parseDig(stdout.String())
+}
+
+// parseDig parses the output of dig run with +noall +answer +stats.
+func parseDig(out string) (*answer, error) {
+	a := &answer{QueryTime: -1}
+
+	s := bufio.NewScanner(strings.NewReader(out))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" {
+			continue
+		}
+
+		if stat, ok := strings.CutPrefix(line, ";; Query time: "); ok {
+			ms, _, _ := strings.Cut(stat, " ")
+			n, err := strconv.Atoi(ms)
+			if err != nil {
+				return nil, fmt.Errorf("invalid query time %q: %w", stat, err)
+			}
+			a.QueryTime = time.Duration(n) * time.Millisecond
+			continue
+		}
+		if strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		// name TTL class type value
+		fields := strings.Fields(line)
+		if len(fields) < 5 {
+			return nil, fmt.Errorf("invalid answer %q", line)
+		}
+		a.Records = append(a.Records, record{Type: fields[3], Value: strings.Join(fields[4:], " ")})
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	if a.QueryTime < 0 {
+		return nil, fmt.Errorf("no query time in dig output: %q", out)
+	}
+	return a, nil
+}
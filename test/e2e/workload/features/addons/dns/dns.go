@@ -0,0 +1,382 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dns
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"text/tabwriter"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/e2e-framework/klient"
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/deployment"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/node"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/pod"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/probe"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/testlabels"
+)
+
+const (
+	appLabel = "app"
+	appName  = "dns-backend"
+
+	// latencySamples is the number of queries made from each node when
+	// measuring resolution latency.
+	latencySamples = 10
+)
+
+type envKey struct{}
+
+// env contains the pods and Services that DNS queries are made from and
+// for. A client pod is deployed to every schedulable node.
+type env struct {
+	domain   string
+	nodes    []string
+	clients  map[string]*corev1.Pod
+	backends []*corev1.Pod
+
+	service      *corev1.Service
+	headless     *corev1.Service
+	externalName *corev1.Service
+
+	// created are the objects created so far, which may be fewer than all
+	// of the above if Setup fails.
+	created []k8s.Object
+}
+
+// fqdn returns the fully qualified name of the Service.
+func (e *env) fqdn(svc *corev1.Service) string {
+	return fmt.Sprintf("%s.%s.svc.%s.", svc.Name, svc.Namespace, e.domain)
+}
+
+// Features returns a list of DNS test features to be run in a given context
+func Features(t *testing.T, tc *framework.TestContextType) []features.Feature {
+	return []features.Feature{
+		Feature(t, tc),
+	}
+}
+
+// Feature returns a test feature for cluster DNS
+func Feature(t *testing.T, tc *framework.TestContextType) features.Feature {
+	builder := features.New("dns")
+	builder.WithLabel(testlabels.WorkloadCluster())
+
+	builder.Setup(func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		nodes, err := node.ListSchedulable(ctx, c.Client())
+		if err != nil {
+			t.Fatalf("unable to list schedulable nodes: %s", err)
+		}
+		if len(nodes) == 0 {
+			t.Fatal("no schedulable nodes found")
+		}
+
+		e := &env{
+			domain:  tc.ClusterDomain,
+			clients: make(map[string]*corev1.Pod, len(nodes)),
+		}
+		selector := map[string]string{appLabel: appName}
+		e.service = &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: appName, Namespace: c.Namespace()},
+			Spec: corev1.ServiceSpec{
+				Selector: selector,
+				Ports:    []corev1.ServicePort{{Port: 80, TargetPort: intstr.FromInt32(probe.Port)}},
+			},
+		}
+		e.headless = &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: appName + "-headless", Namespace: c.Namespace()},
+			Spec: corev1.ServiceSpec{
+				ClusterIP: corev1.ClusterIPNone,
+				Selector:  selector,
+				Ports:     []corev1.ServicePort{{Port: probe.Port}},
+			},
+		}
+		e.externalName = &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: appName + "-external", Namespace: c.Namespace()},
+			Spec: corev1.ServiceSpec{
+				Type:         corev1.ServiceTypeExternalName,
+				ExternalName: strings.TrimSuffix(e.fqdn(e.service), "."),
+			},
+		}
+
+		var pods []*corev1.Pod
+		for i := 0; i < 2; i++ {
+			p := probe.NewPod(fmt.Sprintf("%s-%d", appName, i), c.Namespace(), "", selector)
+			e.backends = append(e.backends, p)
+			pods = append(pods, p)
+		}
+		for i, n := range nodes {
+			p := newClientPod(fmt.Sprintf("dns-client-%d", i), c.Namespace(), n.Name)
+			e.nodes = append(e.nodes, n.Name)
+			e.clients[n.Name] = p
+			pods = append(pods, p)
+		}
+
+		// Teardown is skipped when Setup fails, so the objects created so
+		// far are deleted before failing to keep them out of the shared
+		// namespace.
+		fail := func(format string, args ...any) {
+			t.Helper()
+			e.delete(ctx, t, c.Client())
+			t.Fatalf(format, args...)
+		}
+
+		objs := []k8s.Object{e.service, e.headless, e.externalName}
+		for _, p := range pods {
+			objs = append(objs, p)
+		}
+		for _, obj := range objs {
+			if err := c.Client().Resources().Create(ctx, obj); err != nil {
+				fail("unable to create %s: %s", obj.GetName(), err)
+			}
+			e.created = append(e.created, obj)
+		}
+		if err := pod.WaitForReady(ctx, c.Client(), pods...); err != nil {
+			fail("%s", err)
+		}
+
+		return context.WithValue(ctx, envKey{}, e)
+	})
+
+	builder.Assess("Deployment Running", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		deployment.AssertAvailable(ctx, t, c.Client(), "kube-system", "coredns")
+		return ctx
+	})
+
+	builder.Assess("Service FQDN resolves", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		e := ctx.Value(envKey{}).(*env)
+
+		var kubernetes corev1.Service
+		if err := c.Client().Resources().Get(ctx, "kubernetes", "default", &kubernetes); err != nil {
+			t.Fatalf("unable to get service default/kubernetes: %s", err)
+		}
+
+		for _, svc := range []*corev1.Service{&kubernetes, e.service} {
+			expected := []string{svc.Spec.ClusterIP}
+			e.resolve(ctx, t, c, e.fqdn(svc), "A", func(a *answer) error {
+				return equalValues(a.values("A"), expected)
+			})
+		}
+
+		return ctx
+	})
+
+	builder.Assess("Headless Service resolves to pod IPs", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		e := ctx.Value(envKey{}).(*env)
+
+		expected := make([]string, 0, len(e.backends))
+		for _, p := range e.backends {
+			expected = append(expected, p.Status.PodIP)
+		}
+		e.resolve(ctx, t, c, e.fqdn(e.headless), "A", func(a *answer) error {
+			return equalValues(a.values("A"), expected)
+		})
+
+		return ctx
+	})
+
+	builder.Assess("ExternalName Service resolves", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		e := ctx.Value(envKey{}).(*env)
+
+		target := e.externalName.Spec.ExternalName + "."
+		e.resolve(ctx, t, c, e.fqdn(e.externalName), "A", func(a *answer) error {
+			if err := equalValues(a.values("CNAME"), []string{target}); err != nil {
+				return fmt.Errorf("CNAME: %w", err)
+			}
+			if !slices.Contains(a.values("A"), e.service.Spec.ClusterIP) {
+				return fmt.Errorf("CNAME %s did not resolve to %s, got %v", target, e.service.Spec.ClusterIP, a.values("A"))
+			}
+			return nil
+		})
+
+		return ctx
+	})
+
+	builder.Assess("External name resolves", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		if tc.ExternalDNSName == "" {
+			t.Skip("no external DNS name configured")
+		}
+		e := ctx.Value(envKey{}).(*env)
+
+		// Names outside of the cluster domain are forwarded to the upstream
+		// resolvers, which is what pods rely on to reach external services.
+		name := strings.TrimSuffix(tc.ExternalDNSName, ".") + "."
+		e.resolve(ctx, t, c, name, "A", func(a *answer) error {
+			if len(a.values("A")) == 0 {
+				return fmt.Errorf("expected at least one record, got none")
+			}
+			return nil
+		})
+
+		return ctx
+	})
+
+	builder.Assess("Resolution latency", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		e := ctx.Value(envKey{}).(*env)
+
+		var (
+			mu      sync.Mutex
+			wg      sync.WaitGroup
+			samples = make(map[string][]time.Duration, len(e.nodes))
+			errs    = make(map[string]error, len(e.nodes))
+		)
+		for _, n := range e.nodes {
+			wg.Add(1)
+			go func(n string) {
+				defer wg.Done()
+
+				var times []time.Duration
+				var err error
+				for i := 0; i < latencySamples && err == nil; i++ {
+					var a *answer
+					if a, err = lookup(ctx, c.Client(), e.clients[n], e.fqdn(e.service), "A"); err == nil {
+						times = append(times, a.QueryTime)
+					}
+				}
+				slices.Sort(times)
+
+				mu.Lock()
+				defer mu.Unlock()
+				samples[n] = times
+				errs[n] = err
+			}(n)
+		}
+		wg.Wait()
+
+		var b strings.Builder
+		w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NODE\tP50\tMAX\t")
+		for _, n := range e.nodes {
+			times := samples[n]
+			if len(times) == 0 {
+				fmt.Fprintf(w, "%s\t-\t-\t\n", n)
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t\n", n, times[len(times)/2], times[len(times)-1])
+		}
+		w.Flush()
+		t.Logf("dns resolution latency:\n%s", b.String())
+
+		for _, n := range e.nodes {
+			times := samples[n]
+			switch {
+			case errs[n] != nil:
+				t.Errorf("node %s: unable to resolve %s: %s", n, e.fqdn(e.service), errs[n])
+			case times[len(times)-1] > tc.MaxDNSLatency:
+				t.Errorf("node %s: resolving %s took up to %s, expected at most %s",
+					n, e.fqdn(e.service), times[len(times)-1], tc.MaxDNSLatency)
+			}
+		}
+
+		return ctx
+	})
+
+	builder.Teardown(func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		e, ok := ctx.Value(envKey{}).(*env)
+		if !ok {
+			return ctx
+		}
+
+		e.delete(ctx, t, c.Client())
+		return ctx
+	})
+
+	return builder.Feature()
+}
+
+// delete deletes the pods and Services that were created.
+func (e *env) delete(ctx context.Context, t *testing.T, c klient.Client) {
+	t.Helper()
+
+	for _, obj := range e.created {
+		if err := c.Resources().Delete(ctx, obj); err != nil {
+			t.Errorf("unable to delete %s: %s", obj.GetName(), err)
+		}
+	}
+}
+
+// resolve queries name from the client pod on every node in parallel until
+// the answer is accepted by check or the timeout passes. Every node on which
+// the answer was not accepted is reported.
+func (e *env) resolve(ctx context.Context, t *testing.T, c *envconf.Config, name, recordType string, check func(*answer) error) {
+	t.Helper()
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]error, len(e.nodes))
+	)
+	for _, n := range e.nodes {
+		wg.Add(1)
+		go func(n string) {
+			defer wg.Done()
+
+			// Records for new Services and endpoints take some time to be
+			// served, so the query is repeated until it is accepted.
+			var last error
+			_ = wait.For(func(ctx context.Context) (bool, error) {
+				a, err := lookup(ctx, c.Client(), e.clients[n], name, recordType)
+				if err == nil {
+					err = check(a)
+				}
+				last = err
+				return err == nil, nil
+			},
+				wait.WithContext(ctx),
+				wait.WithTimeout(framework.NewTimeoutContext().PodStart),
+				wait.WithInterval(framework.PollInterval()),
+				wait.WithImmediate())
+
+			mu.Lock()
+			defer mu.Unlock()
+			results[n] = last
+		}(n)
+	}
+	wg.Wait()
+
+	for _, n := range e.nodes {
+		if err := results[n]; err != nil {
+			t.Errorf("node %s: %s %s: %s", n, recordType, name, err)
+		}
+	}
+}
+
+// equalValues returns an error unless actual and expected contain the same
+// values in any order.
+func equalValues(actual, expected []string) error {
+	a := append([]string{}, actual...)
+	x := append([]string{}, expected...)
+	sort.Strings(a)
+	sort.Strings(x)
+	if !slices.Equal(a, x) {
+		return fmt.Errorf("expected %v, got %v", x, a)
+	}
+	return nil
+}
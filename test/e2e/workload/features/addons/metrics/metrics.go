@@ -0,0 +1,193 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/e2e-framework/klient"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/deployment"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/kinds"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/node"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/testlabels"
+)
+
+const (
+	apiServiceName = "v1beta1.metrics.k8s.io"
+
+	// minPodAge is how long a pod must have been running before metrics are
+	// expected for it, allowing metrics-server to scrape it at least once.
+	minPodAge = 2 * time.Minute
+)
+
+// Features returns a list of metrics test features to be run in a given context
+func Features(t *testing.T, tc *framework.TestContextType) []features.Feature {
+	return []features.Feature{
+		Feature(t, tc),
+	}
+}
+
+// Feature returns a test feature for metrics-server and the resource metrics
+// API it serves through API aggregation
+func Feature(t *testing.T, tc *framework.TestContextType) features.Feature {
+	builder := features.New("metrics-server")
+	builder.WithLabel(testlabels.WorkloadCluster())
+
+	builder.Assess("Deployment Running", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		deployment.AssertAvailable(ctx, t, c.Client(), "kube-system", "metrics-server")
+		return ctx
+	})
+
+	builder.Assess("APIService is Available", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		svc := kinds.New(kinds.APIService)
+		var last kinds.Condition
+		err := wait.For(func(ctx context.Context) (bool, error) {
+			if err := c.Client().Resources().Get(ctx, apiServiceName, "", svc); err != nil {
+				return false, err
+			}
+			cond, ok := kinds.GetCondition(svc, "Available")
+			last = cond
+			return ok && cond.IsTrue(), nil
+		},
+			wait.WithContext(ctx),
+			wait.WithTimeout(framework.NewTimeoutContext().AddonReady),
+			wait.WithInterval(framework.PollInterval()),
+			wait.WithImmediate())
+		if err != nil {
+			t.Fatalf("APIService %s is not available: condition Available is %q: %s: %s: %s",
+				apiServiceName, last.Status, last.Reason, last.Message, err)
+		}
+
+		return ctx
+	})
+
+	builder.Assess("Node metrics are reported for every node", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		nodes, err := node.List(ctx, c.Client())
+		if err != nil {
+			t.Fatalf("unable to list nodes: %s", err)
+		}
+
+		expected := make([]string, 0, len(nodes))
+		for _, n := range nodes {
+			expected = append(expected, n.Name)
+		}
+
+		missing := waitForMetrics(ctx, c.Client(), kinds.NodeMetrics, "", expected, nodeHasUsage)
+		for _, name := range missing {
+			t.Errorf("no resource usage reported for node %s", name)
+		}
+
+		return ctx
+	})
+
+	builder.Assess("Pod metrics are reported", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		var pods corev1.PodList
+		if err := c.Client().Resources("kube-system").List(ctx, &pods); err != nil {
+			t.Fatalf("unable to list pods in kube-system: %s", err)
+		}
+
+		var expected []string
+		for _, p := range pods.Items {
+			if p.Status.Phase == corev1.PodRunning && p.Status.StartTime != nil && time.Since(p.Status.StartTime.Time) > minPodAge {
+				expected = append(expected, p.Name)
+			}
+		}
+		if len(expected) == 0 {
+			t.Fatalf("no pods in kube-system have been running for at least %s", minPodAge)
+		}
+
+		missing := waitForMetrics(ctx, c.Client(), kinds.PodMetrics, "kube-system", expected, podHasUsage)
+		for _, name := range missing {
+			t.Errorf("no resource usage reported for pod kube-system/%s", name)
+		}
+
+		return ctx
+	})
+
+	return builder.Feature()
+}
+
+// waitForMetrics lists the metrics of the given kind until every expected
+// object has usage reported or the timeout passes, and returns the names of
+// the objects that still have no usage.
+func waitForMetrics(ctx context.Context, c klient.Client, gvk schema.GroupVersionKind, namespace string, expected []string, hasUsage func(*unstructured.Unstructured) bool) []string {
+	missing := append([]string{}, expected...)
+	_ = wait.For(func(ctx context.Context) (bool, error) {
+		list := kinds.NewList(gvk)
+		if err := c.Resources(namespace).List(ctx, list); err != nil {
+			// The aggregated API may be briefly unavailable while
+			// metrics-server scrapes for the first time.
+			return false, nil
+		}
+
+		reported := make(map[string]bool, len(list.Items))
+		for i := range list.Items {
+			if hasUsage(&list.Items[i]) {
+				reported[list.Items[i].GetName()] = true
+			}
+		}
+
+		missing = nil
+		for _, name := range expected {
+			if !reported[name] {
+				missing = append(missing, name)
+			}
+		}
+		return len(missing) == 0, nil
+	},
+		wait.WithContext(ctx),
+		wait.WithTimeout(framework.NewTimeoutContext().AddonReady),
+		wait.WithInterval(framework.PollInterval()),
+		wait.WithImmediate())
+
+	sort.Strings(missing)
+	return missing
+}
+
+// nodeHasUsage reports whether the NodeMetrics has CPU and memory usage.
+func nodeHasUsage(m *unstructured.Unstructured) bool {
+	usage, _, _ := unstructured.NestedStringMap(m.Object, "usage")
+	return usage[string(corev1.ResourceCPU)] != "" && usage[string(corev1.ResourceMemory)] != ""
+}
+
+// podHasUsage reports whether the PodMetrics has CPU and memory usage for at
+// least one container.
+func podHasUsage(m *unstructured.Unstructured) bool {
+	containers, _, _ := unstructured.NestedSlice(m.Object, "containers")
+	for _, raw := range containers {
+		container, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		usage, _, _ := unstructured.NestedStringMap(container, "usage")
+		if usage[string(corev1.ResourceCPU)] != "" && usage[string(corev1.ResourceMemory)] != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,283 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificates
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/node"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/testlabels"
+)
+
+// Features returns a list of certificate test features to be run in a given
+// context
+func Features(t *testing.T, tc *framework.TestContextType) []features.Feature {
+	return []features.Feature{
+		Feature(t, tc),
+	}
+}
+
+// Feature returns a test feature that checks the certificates of the workload
+// cluster, and those stored for it on the Kubernetes Service, do not expire
+// within the configured window
+func Feature(t *testing.T, tc *framework.TestContextType) features.Feature {
+	builder := features.New("certificates")
+	builder.WithLabel(testlabels.WorkloadCluster())
+
+	builder.Setup(func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		nodes, err := node.List(ctx, c.Client())
+		if err != nil {
+			t.Fatalf("unable to list nodes: %s", err)
+		}
+
+		r, err := newReaders(ctx, c.Client(), nodes)
+		if err != nil {
+			// Teardown is skipped when Setup fails, so the privileged
+			// readers are deleted here instead.
+			if r != nil {
+				if err := r.delete(ctx, c.Client()); err != nil {
+					t.Errorf("unable to delete certificate readers: %s", err)
+				}
+			}
+			t.Fatalf("unable to create certificate readers: %s", err)
+		}
+		return context.WithValue(ctx, readersKey{}, r)
+	})
+
+	builder.Assess("kube-apiserver endpoint certificate is valid", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		cfg := c.Client().RESTConfig()
+		certs, err := dialCertificates(ctx, cfg.Host, cfg.TLSClientConfig.ServerName)
+		if err != nil {
+			t.Fatalf("unable to get serving certificate of %s: %s", cfg.Host, err)
+		}
+		// Only the leaf is served by the API server, the rest of the chain
+		// is checked through the CA Secret.
+		assertValid(t, fmt.Sprintf("kube-apiserver endpoint %s", cfg.Host), certs[:1], tc.CertificateExpiryWindow)
+		return ctx
+	})
+
+	builder.Assess("Control plane serving certificates are valid", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		r := ctx.Value(readersKey{}).(*readers)
+		for _, n := range r.controlPlane() {
+			for _, f := range controlPlaneFiles {
+				certs, err := r.read(ctx, c.Client(), n, f.path)
+				if err != nil {
+					t.Errorf("unable to read %s certificate on node %s: %s", f.name, n, err)
+					continue
+				}
+				assertValid(t, fmt.Sprintf("%s on node %s", f.name, n), certs, tc.CertificateExpiryWindow)
+			}
+		}
+		return ctx
+	})
+
+	builder.Assess("Kubelet serving certificates are valid", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		r := ctx.Value(readersKey{}).(*readers)
+		for _, n := range r.all() {
+			certs, err := r.readKubelet(ctx, c.Client(), n)
+			if err != nil {
+				t.Errorf("unable to read kubelet certificate on node %s: %s", n, err)
+				continue
+			}
+			assertValid(t, fmt.Sprintf("kubelet on node %s", n), certs[:1], tc.CertificateExpiryWindow)
+		}
+		return ctx
+	})
+
+	builder.Assess("CA and kubeconfig Secrets are valid", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		if tc.SupervisorKubeconfig == "" {
+			t.Skip("-supervisor-kubeconfig is required to inspect the Secrets of the cluster")
+		}
+
+		supervisor, err := tc.SupervisorClient()
+		if err != nil {
+			t.Fatalf("unable to create Kubernetes Service client: %s", err)
+		}
+
+		for _, suffix := range caSecrets {
+			name := fmt.Sprintf("%s-%s", tc.ClusterName, suffix)
+			var secret corev1.Secret
+			if err := supervisor.Resources().Get(ctx, name, tc.ClusterNamespace, &secret); err != nil {
+				t.Errorf("unable to get Secret %s/%s: %s", tc.ClusterNamespace, name, err)
+				continue
+			}
+
+			certs, err := parseCertificates(secret.Data[corev1.TLSCertKey])
+			if err != nil {
+				t.Errorf("unable to parse Secret %s/%s: %s", tc.ClusterNamespace, name, err)
+				continue
+			}
+			assertValid(t, fmt.Sprintf("Secret %s/%s", tc.ClusterNamespace, name), certs, tc.CertificateExpiryWindow)
+		}
+
+		name := fmt.Sprintf("%s-kubeconfig", tc.ClusterName)
+		var secret corev1.Secret
+		if err := supervisor.Resources().Get(ctx, name, tc.ClusterNamespace, &secret); err != nil {
+			t.Fatalf("unable to get Secret %s/%s: %s", tc.ClusterNamespace, name, err)
+		}
+		assertKubeconfigValid(t, fmt.Sprintf("Secret %s/%s", tc.ClusterNamespace, name), secret.Data["value"], tc.CertificateExpiryWindow)
+
+		return ctx
+	})
+
+	builder.Teardown(func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		r, ok := ctx.Value(readersKey{}).(*readers)
+		if !ok {
+			return ctx
+		}
+
+		if err := r.delete(ctx, c.Client()); err != nil {
+			t.Errorf("unable to delete certificate readers: %s", err)
+		}
+		return ctx
+	})
+
+	return builder.Feature()
+}
+
+// caSecrets are the suffixes of the Secrets Cluster API stores the
+// certificate authorities of a cluster in.
+var caSecrets = []string{"ca", "etcd", "proxy"}
+
+// assertValid reports the subject and expiry of each certificate and checks
+// that none of them expire within the window.
+func assertValid(t *testing.T, source string, certs []*x509.Certificate, window time.Duration) {
+	t.Helper()
+
+	deadline := time.Now().Add(window)
+	for _, cert := range certs {
+		t.Logf("%s: %q expires %s", source, cert.Subject, cert.NotAfter.Format(time.RFC3339))
+
+		switch {
+		case time.Now().After(cert.NotAfter):
+			t.Errorf("%s: %q expired %s", source, cert.Subject, cert.NotAfter.Format(time.RFC3339))
+		case deadline.After(cert.NotAfter):
+			t.Errorf("%s: %q expires %s, within %s", source, cert.Subject, cert.NotAfter.Format(time.RFC3339), window)
+		}
+	}
+}
+
+// assertKubeconfigValid checks the certificate authorities and client
+// certificates embedded in the kubeconfig.
+func assertKubeconfigValid(t *testing.T, source string, data []byte, window time.Duration) {
+	t.Helper()
+
+	cfg, err := clientcmd.Load(data)
+	if err != nil {
+		t.Errorf("unable to parse kubeconfig in %s: %s", source, err)
+		return
+	}
+
+	for name, cluster := range cfg.Clusters {
+		certs, err := parseCertificates(cluster.CertificateAuthorityData)
+		if err != nil {
+			t.Errorf("unable to parse certificate authority of cluster %s in %s: %s", name, source, err)
+			continue
+		}
+		assertValid(t, fmt.Sprintf("%s cluster %s", source, name), certs, window)
+	}
+
+	for name, user := range cfg.AuthInfos {
+		// Users may authenticate with a token instead.
+		if len(user.ClientCertificateData) == 0 {
+			continue
+		}
+		certs, err := parseCertificates(user.ClientCertificateData)
+		if err != nil {
+			t.Errorf("unable to parse client certificate of user %s in %s: %s", name, source, err)
+			continue
+		}
+		assertValid(t, fmt.Sprintf("%s user %s", source, name), certs, window)
+	}
+}
+
+// parseCertificates parses every certificate in the PEM data, ignoring any
+// other blocks such as private keys.
+func parseCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificates found")
+	}
+	return certs, nil
+}
+
+// dialCertificates returns the certificate chain served at the host of the
+// URL. The chain is only inspected, so it is not verified.
+func dialCertificates(ctx context.Context, rawURL, serverName string) ([]*x509.Certificate, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "443")
+	}
+	if serverName == "" {
+		serverName = u.Hostname()
+	}
+
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: 10 * time.Second},
+		Config: &tls.Config{
+			ServerName: serverName,
+			// The certificate is only inspected, never trusted.
+			InsecureSkipVerify: true,
+		},
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	certs := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificates served")
+	}
+	return certs, nil
+}
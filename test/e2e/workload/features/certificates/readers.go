@@ -0,0 +1,208 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificates
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/e2e-framework/klient"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/pod"
+)
+
+const (
+	image     = "registry.k8s.io/e2e-test-images/busybox:1.36.1-1"
+	container = "reader"
+
+	controlPlaneLabel = "node-role.kubernetes.io/control-plane"
+
+	pkiDir        = "/etc/kubernetes/pki"
+	kubeletPKIDir = "/var/lib/kubelet/pki"
+)
+
+// controlPlaneFiles are the serving certificates kubeadm writes on every
+// control plane node.
+var controlPlaneFiles = []struct {
+	name string
+	path string
+}{
+	{"kube-apiserver", pkiDir + "/apiserver.crt"},
+	{"etcd", pkiDir + "/etcd/server.crt"},
+}
+
+// kubeletFiles are the serving certificates of the kubelet, either rotated
+// through the certificates API or self-signed, in order of preference.
+var kubeletFiles = []string{
+	kubeletPKIDir + "/kubelet-server-current.pem",
+	kubeletPKIDir + "/kubelet.crt",
+}
+
+type readersKey struct{}
+
+// readers are pods on every node that mount its certificate directories
+// read-only, so that the certificates can be read without SSH access.
+type readers struct {
+	namespace *corev1.Namespace
+
+	// pods are keyed by node name.
+	pods map[string]*corev1.Pod
+
+	// controlPlaneNodes are the names of the nodes running the control
+	// plane.
+	controlPlaneNodes map[string]bool
+}
+
+// newReaders creates a reader pod on every node and waits for them to be
+// ready. The namespace allows privileged pods as reading the host requires
+// hostPath volumes. The readers are returned even on error so that they can
+// be deleted.
+func newReaders(ctx context.Context, c klient.Client, nodes []corev1.Node) (*readers, error) {
+	r := &readers{
+		namespace: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:   envconf.RandomName("certificates", 20),
+			Labels: map[string]string{"pod-security.kubernetes.io/enforce": "privileged"},
+		}},
+		pods:              make(map[string]*corev1.Pod, len(nodes)),
+		controlPlaneNodes: map[string]bool{},
+	}
+	if err := c.Resources().Create(ctx, r.namespace); err != nil {
+		return nil, fmt.Errorf("creating namespace %s: %w", r.namespace.Name, err)
+	}
+
+	for i, n := range nodes {
+		_, controlPlane := n.Labels[controlPlaneLabel]
+		if controlPlane {
+			r.controlPlaneNodes[n.Name] = true
+		}
+
+		p := newReaderPod(fmt.Sprintf("reader-%d", i), r.namespace.Name, n.Name, controlPlane)
+		if err := c.Resources().Create(ctx, p); err != nil {
+			return r, fmt.Errorf("creating pod %s/%s: %w", p.Namespace, p.Name, err)
+		}
+		r.pods[n.Name] = p
+	}
+
+	pods := make([]*corev1.Pod, 0, len(r.pods))
+	for _, p := range r.pods {
+		pods = append(pods, p)
+	}
+	return r, pod.WaitForReady(ctx, c, pods...)
+}
+
+// newReaderPod returns a pod bound to the node that mounts the kubelet
+// certificates and, on control plane nodes, the control plane certificates.
+func newReaderPod(name, namespace, node string, controlPlane bool) *corev1.Pod {
+	dirs := []string{kubeletPKIDir}
+	if controlPlane {
+		dirs = append(dirs, pkiDir)
+	}
+
+	p := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: corev1.PodSpec{
+			NodeName:    node,
+			Tolerations: []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+			Containers: []corev1.Container{{
+				Name:    container,
+				Image:   image,
+				Command: []string{"sleep", "infinity"},
+			}},
+		},
+	}
+
+	for i, dir := range dirs {
+		volume := fmt.Sprintf("pki-%d", i)
+		p.Spec.Volumes = append(p.Spec.Volumes, corev1.Volume{
+			Name: volume,
+			VolumeSource: corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{Path: dir},
+			},
+		})
+		p.Spec.Containers[0].VolumeMounts = append(p.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name:      volume,
+			MountPath: dir,
+			ReadOnly:  true,
+		})
+	}
+
+	return p
+}
+
+// all returns the names of every node, sorted.
+func (r *readers) all() []string {
+	names := make([]string, 0, len(r.pods))
+	for n := range r.pods {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// controlPlane returns the names of the control plane nodes, sorted.
+func (r *readers) controlPlane() []string {
+	var names []string
+	for _, n := range r.all() {
+		if r.controlPlaneNodes[n] {
+			names = append(names, n)
+		}
+	}
+	return names
+}
+
+// read returns the certificates in the file on the node.
+func (r *readers) read(ctx context.Context, c klient.Client, node, path string) ([]*x509.Certificate, error) {
+	p, ok := r.pods[node]
+	if !ok {
+		return nil, fmt.Errorf("no reader on node %s", node)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if err := c.Resources().ExecInPod(ctx, p.Namespace, p.Name, container, []string{"cat", path}, &stdout, &stderr); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %s", msg, err)
+		}
+		return nil, err
+	}
+	return parseCertificates(stdout.Bytes())
+}
+
+// readKubelet returns the certificates of the first kubelet serving
+// certificate file found on the node.
+func (r *readers) readKubelet(ctx context.Context, c klient.Client, node string) ([]*x509.Certificate, error) {
+	var errs []string
+	for _, path := range kubeletFiles {
+		certs, err := r.read(ctx, c, node, path)
+		if err == nil {
+			return certs, nil
+		}
+		errs = append(errs, fmt.Sprintf("%s: %s", path, err))
+	}
+	return nil, fmt.Errorf("%s", strings.Join(errs, "; "))
+}
+
+// delete deletes the namespace of the readers along with every pod.
+func (r *readers) delete(ctx context.Context, c klient.Client) error {
+	return c.Resources().Delete(ctx, r.namespace)
+}
@@ -0,0 +1,222 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controlplane
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/node"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/pod"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/testlabels"
+)
+
+const controlPlaneLabel = "node-role.kubernetes.io/control-plane"
+
+// components are the static pods run by kubeadm on every control plane node,
+// identified by their component label.
+var components = []string{"kube-apiserver", "kube-controller-manager", "kube-scheduler", "etcd"}
+
+// leases are the leader election Leases held by a single instance of a
+// control plane component.
+var leases = []string{"kube-controller-manager", "kube-scheduler"}
+
+// Features returns a list of control plane test features to be run in a given
+// context
+func Features(t *testing.T, tc *framework.TestContextType) []features.Feature {
+	return []features.Feature{
+		Feature(t, tc),
+	}
+}
+
+// Feature returns a test feature for the health of the control plane
+// components of the workload cluster
+func Feature(t *testing.T, tc *framework.TestContextType) features.Feature {
+	builder := features.New("control plane")
+	builder.WithLabel(testlabels.WorkloadCluster())
+
+	builder.Assess("Components are Running on every control plane node", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		nodes := controlPlaneNodes(ctx, t, c)
+
+		for _, component := range components {
+			pods := componentPods(ctx, t, c, component)
+
+			running := make(map[string]bool, len(pods))
+			for i := range pods {
+				if pod.IsReady(&pods[i]) {
+					running[pods[i].Spec.NodeName] = true
+				}
+			}
+			for _, n := range nodes {
+				if !running[n.Name] {
+					t.Errorf("control plane node %s has no ready %s pod", n.Name, component)
+				}
+			}
+
+			pod.AssertRestartsWithin(t, pods, tc.MaxContainerRestarts)
+		}
+		return ctx
+	})
+
+	builder.Assess("Component versions are consistent", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		for _, component := range components {
+			versions := map[string][]string{}
+			for _, p := range componentPods(ctx, t, c, component) {
+				for _, ctr := range p.Spec.Containers {
+					v := imageTag(ctr.Image)
+					versions[v] = append(versions[v], p.Spec.NodeName)
+				}
+			}
+
+			if len(versions) > 1 {
+				t.Errorf("%s runs different versions across control plane nodes: %v", component, versions)
+			}
+		}
+		return ctx
+	})
+
+	builder.Assess("Leader election leases are held and renewing", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		nodes := controlPlaneNodes(ctx, t, c)
+
+		for _, name := range leases {
+			var lease coordinationv1.Lease
+			if err := c.Client().Resources().Get(ctx, name, "kube-system", &lease); err != nil {
+				t.Errorf("unable to get lease kube-system/%s: %s", name, err)
+				continue
+			}
+
+			// Holder identities are of the form <node>_<uuid>.
+			holder := ""
+			if lease.Spec.HolderIdentity != nil {
+				holder = *lease.Spec.HolderIdentity
+			}
+			if !heldByNode(holder, nodes) {
+				t.Errorf("lease kube-system/%s is held by %q which is not a control plane node", name, holder)
+				continue
+			}
+
+			assertRenewing(ctx, t, c, &lease)
+		}
+		return ctx
+	})
+
+	builder.Assess("etcd members match control plane replicas", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		assertEtcdMembers(ctx, t, c, tc, controlPlaneNodes(ctx, t, c))
+		return ctx
+	})
+
+	return builder.Feature()
+}
+
+// controlPlaneNodes returns the control plane nodes of the cluster, failing
+// the test if there are none.
+func controlPlaneNodes(ctx context.Context, t *testing.T, c *envconf.Config) []corev1.Node {
+	t.Helper()
+
+	nodes, err := node.List(ctx, c.Client())
+	if err != nil {
+		t.Fatalf("unable to list nodes: %s", err)
+	}
+
+	var controlPlane []corev1.Node
+	for _, n := range nodes {
+		if _, ok := n.Labels[controlPlaneLabel]; ok {
+			controlPlane = append(controlPlane, n)
+		}
+	}
+	if len(controlPlane) == 0 {
+		t.Fatalf("unable to find nodes labeled %s", controlPlaneLabel)
+	}
+	return controlPlane
+}
+
+// componentPods returns the static pods of the control plane component.
+func componentPods(ctx context.Context, t *testing.T, c *envconf.Config, component string) []corev1.Pod {
+	t.Helper()
+
+	var pods corev1.PodList
+	err := c.Client().Resources("kube-system").List(ctx, &pods,
+		resources.WithLabelSelector(
+			labels.FormatLabels(
+				map[string]string{"component": component},
+			)))
+	if err != nil {
+		t.Fatalf("unable to list %s pods: %s", component, err)
+	}
+	return pods.Items
+}
+
+// imageTag returns the tag of the image reference, ignoring any digest.
+func imageTag(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	// The registry host may contain a port, so only the last path element
+	// carries the tag.
+	name := image[strings.LastIndex(image, "/")+1:]
+	if _, tag, ok := strings.Cut(name, ":"); ok {
+		return tag
+	}
+	return "latest"
+}
+
+func heldByNode(holder string, nodes []corev1.Node) bool {
+	name, _, _ := strings.Cut(holder, "_")
+	for _, n := range nodes {
+		if n.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// assertRenewing checks that the holder of the lease renews it before it
+// would expire.
+func assertRenewing(ctx context.Context, t *testing.T, c *envconf.Config, lease *coordinationv1.Lease) {
+	t.Helper()
+
+	if lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		t.Errorf("lease %s/%s has never been renewed", lease.Namespace, lease.Name)
+		return
+	}
+	last := lease.Spec.RenewTime.Time
+	duration := time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second
+
+	err := wait.For(func(ctx context.Context) (bool, error) {
+		var current coordinationv1.Lease
+		if err := c.Client().Resources().Get(ctx, lease.Name, lease.Namespace, &current); err != nil {
+			return false, nil
+		}
+		return current.Spec.RenewTime != nil && current.Spec.RenewTime.After(last), nil
+	},
+		wait.WithContext(ctx),
+		wait.WithTimeout(duration),
+		wait.WithInterval(framework.PollInterval()))
+	if err != nil {
+		t.Errorf("lease %s/%s was not renewed within %s of %s: %s",
+			lease.Namespace, lease.Name, duration, last.Format(time.RFC3339), err)
+	}
+}
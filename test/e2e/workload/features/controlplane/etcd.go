@@ -0,0 +1,128 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controlplane
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/kinds"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/pod"
+)
+
+// etcdctl lists the members of the local etcd using the certificates kubeadm
+// places on every control plane node.
+var etcdctl = []string{
+	"etcdctl",
+	"--endpoints=https://127.0.0.1:2379",
+	"--cacert=/etc/kubernetes/pki/etcd/ca.crt",
+	"--cert=/etc/kubernetes/pki/etcd/server.crt",
+	"--key=/etc/kubernetes/pki/etcd/server.key",
+	"member", "list",
+}
+
+// assertEtcdMembers checks that etcd has one started member per control plane
+// replica. The replicas are read from the KubeadmControlPlane when the
+// Kubernetes Service is accessible, otherwise the control plane nodes are
+// counted.
+func assertEtcdMembers(ctx context.Context, t *testing.T, c *envconf.Config, tc *framework.TestContextType, nodes []corev1.Node) {
+	t.Helper()
+
+	replicas := int64(len(nodes))
+	if tc.SupervisorKubeconfig != "" {
+		replicas = controlPlaneReplicas(ctx, t, tc)
+	} else {
+		t.Logf("-supervisor-kubeconfig is not set, expecting one etcd member per control plane node")
+	}
+
+	var member *corev1.Pod
+	pods := componentPods(ctx, t, c, "etcd")
+	for i := range pods {
+		if pod.IsReady(&pods[i]) {
+			member = &pods[i]
+			break
+		}
+	}
+	if member == nil {
+		t.Fatal("unable to find a ready etcd pod")
+	}
+
+	var stdout, stderr bytes.Buffer
+	err := c.Client().Resources().ExecInPod(ctx, member.Namespace, member.Name, "etcd", etcdctl, &stdout, &stderr)
+	if err != nil {
+		t.Fatalf("unable to list etcd members from pod %s/%s: %s: %s",
+			member.Namespace, member.Name, strings.TrimSpace(stderr.String()), err)
+	}
+
+	// Each member is listed as "<id>, <status>, <name>, <peer urls>, ...".
+	var started int64
+	for _, line := range strings.Split(strings.TrimSpace(stdout.String()), "\n") {
+		fields := strings.Split(line, ", ")
+		if len(fields) < 3 {
+			continue
+		}
+		if fields[1] != "started" {
+			t.Errorf("etcd member %s is %s", fields[2], fields[1])
+			continue
+		}
+		started++
+	}
+
+	if started != replicas {
+		t.Errorf("expected %d etcd members, found %d started:\n%s", replicas, started, stdout.String())
+	}
+}
+
+// controlPlaneReplicas returns the desired replicas of the
+// KubeadmControlPlane of the cluster under test.
+func controlPlaneReplicas(ctx context.Context, t *testing.T, tc *framework.TestContextType) int64 {
+	t.Helper()
+
+	supervisor, err := tc.SupervisorClient()
+	if err != nil {
+		t.Fatalf("unable to create Kubernetes Service client: %s", err)
+	}
+
+	kcps := kinds.NewList(kinds.KubeadmControlPlane)
+	err = supervisor.Resources(tc.ClusterNamespace).List(ctx, kcps,
+		resources.WithLabelSelector(
+			labels.FormatLabels(
+				map[string]string{kinds.ClusterNameLabel: tc.ClusterName},
+			)))
+	if err != nil {
+		t.Fatalf("unable to list KubeadmControlPlanes: %s", err)
+	}
+	if len(kcps.Items) != 1 {
+		t.Fatalf("expected 1 KubeadmControlPlane for cluster %s/%s, found %d",
+			tc.ClusterNamespace, tc.ClusterName, len(kcps.Items))
+	}
+
+	replicas, found, err := unstructured.NestedInt64(kcps.Items[0].Object, "spec", "replicas")
+	if err != nil || !found {
+		t.Fatalf("KubeadmControlPlane %s has no replicas", kcps.Items[0].GetName())
+	}
+	return replicas
+}
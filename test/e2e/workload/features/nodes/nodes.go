@@ -0,0 +1,306 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodes
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/e2e-framework/klient"
+	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/kinds"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/node"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/testlabels"
+)
+
+const (
+	controlPlaneLabel = "node-role.kubernetes.io/control-plane"
+
+	// controlPlanePool is the pool of the control plane nodes, which are not
+	// part of a MachineDeployment.
+	controlPlanePool = "control-plane"
+
+	// workerPool is the pool of every worker node when the Kubernetes
+	// Service is not accessible to tell MachineDeployments apart.
+	workerPool = "workers"
+)
+
+// expectedConditions are the node conditions checked on every node and the
+// status each must have. NetworkUnavailable is only set by some CNIs.
+var expectedConditions = []struct {
+	condType corev1.NodeConditionType
+	status   corev1.ConditionStatus
+	required bool
+}{
+	{corev1.NodeReady, corev1.ConditionTrue, true},
+	{corev1.NodeMemoryPressure, corev1.ConditionFalse, true},
+	{corev1.NodeDiskPressure, corev1.ConditionFalse, true},
+	{corev1.NodePIDPressure, corev1.ConditionFalse, true},
+	{corev1.NodeNetworkUnavailable, corev1.ConditionFalse, false},
+}
+
+// attributes are the versions of a node that must be uniform within a pool.
+var attributes = []struct {
+	name  string
+	value func(*corev1.NodeSystemInfo) string
+}{
+	{"kubelet version", func(i *corev1.NodeSystemInfo) string { return i.KubeletVersion }},
+	{"container runtime version", func(i *corev1.NodeSystemInfo) string { return i.ContainerRuntimeVersion }},
+	{"OS image", func(i *corev1.NodeSystemInfo) string { return i.OSImage }},
+}
+
+type poolsKey struct{}
+
+// pool is a group of nodes that are expected to be identical, such as the
+// nodes of a MachineDeployment.
+type pool struct {
+	name  string
+	nodes []corev1.Node
+
+	// tkr is the name of the TanzuKubernetesRelease the Machines of the pool
+	// were created from, if known.
+	tkr string
+}
+
+// Features returns a list of node test features to be run in a given context
+func Features(t *testing.T, tc *framework.TestContextType) []features.Feature {
+	return []features.Feature{
+		Feature(t, tc),
+	}
+}
+
+// Feature returns a test feature for the health and uniformity of the nodes
+// of the workload cluster
+func Feature(t *testing.T, tc *framework.TestContextType) features.Feature {
+	builder := features.New("nodes")
+	builder.WithLabel(testlabels.WorkloadCluster())
+
+	builder.Setup(func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		nodes, err := node.List(ctx, c.Client())
+		if err != nil {
+			t.Fatalf("unable to list nodes: %s", err)
+		}
+		if len(nodes) == 0 {
+			t.Fatal("unable to find any nodes")
+		}
+
+		var pools []*pool
+		if tc.SupervisorKubeconfig == "" {
+			t.Logf("-supervisor-kubeconfig is not set, grouping nodes into control plane and workers")
+			pools = poolsByRole(nodes)
+		} else {
+			supervisor, err := tc.SupervisorClient()
+			if err != nil {
+				t.Fatalf("unable to create Kubernetes Service client: %s", err)
+			}
+			pools, err = poolsByMachines(ctx, supervisor, tc, nodes)
+			if err != nil {
+				t.Fatalf("unable to group nodes by Machine: %s", err)
+			}
+		}
+
+		return context.WithValue(ctx, poolsKey{}, pools)
+	})
+
+	builder.Assess("Node conditions are healthy", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		for _, p := range ctx.Value(poolsKey{}).([]*pool) {
+			for i := range p.nodes {
+				assertConditions(t, &p.nodes[i])
+			}
+		}
+		return ctx
+	})
+
+	builder.Assess("Node versions are uniform within each pool", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		for _, p := range ctx.Value(poolsKey{}).([]*pool) {
+			for _, attr := range attributes {
+				values := make(map[string]string, len(p.nodes))
+				for i := range p.nodes {
+					values[p.nodes[i].Name] = attr.value(&p.nodes[i].Status.NodeInfo)
+				}
+
+				// Drift is reported against the most common value so that
+				// only the nodes that differ are called out.
+				common := mostCommon(values)
+				for _, n := range sortedKeys(values) {
+					if values[n] != common {
+						t.Errorf("node %s in pool %s has %s %q, the rest of the pool has %q",
+							n, p.name, attr.name, values[n], common)
+					}
+				}
+			}
+		}
+		return ctx
+	})
+
+	builder.Assess("Node versions match the TanzuKubernetesRelease", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		if tc.SupervisorKubeconfig == "" {
+			t.Skip("-supervisor-kubeconfig is required to find the TanzuKubernetesRelease of each pool")
+		}
+
+		supervisor, err := tc.SupervisorClient()
+		if err != nil {
+			t.Fatalf("unable to create Kubernetes Service client: %s", err)
+		}
+
+		for _, p := range ctx.Value(poolsKey{}).([]*pool) {
+			assertMatchesRelease(ctx, t, supervisor, p)
+		}
+		return ctx
+	})
+
+	return builder.Feature()
+}
+
+// poolsByRole groups the nodes into the control plane and the workers.
+func poolsByRole(nodes []corev1.Node) []*pool {
+	controlPlane := &pool{name: controlPlanePool}
+	workers := &pool{name: workerPool}
+	for _, n := range nodes {
+		if _, ok := n.Labels[controlPlaneLabel]; ok {
+			controlPlane.nodes = append(controlPlane.nodes, n)
+		} else {
+			workers.nodes = append(workers.nodes, n)
+		}
+	}
+
+	var pools []*pool
+	for _, p := range []*pool{controlPlane, workers} {
+		if len(p.nodes) > 0 {
+			pools = append(pools, p)
+		}
+	}
+	return pools
+}
+
+// poolsByMachines groups the nodes by the control plane or MachineDeployment
+// of their Machine on the Kubernetes Service.
+func poolsByMachines(ctx context.Context, supervisor klient.Client, tc *framework.TestContextType, nodes []corev1.Node) ([]*pool, error) {
+	cluster := kinds.New(kinds.Cluster)
+	if err := supervisor.Resources().Get(ctx, tc.ClusterName, tc.ClusterNamespace, cluster); err != nil {
+		return nil, err
+	}
+
+	machines := kinds.NewList(kinds.Machine)
+	err := supervisor.Resources(tc.ClusterNamespace).List(ctx, machines,
+		resources.WithLabelSelector(
+			labels.FormatLabels(
+				map[string]string{kinds.ClusterNameLabel: tc.ClusterName},
+			)))
+	if err != nil {
+		return nil, err
+	}
+
+	byNode := make(map[string]*unstructured.Unstructured, len(machines.Items))
+	for i := range machines.Items {
+		name, _, _ := unstructured.NestedString(machines.Items[i].Object, "status", "nodeRef", "name")
+		byNode[name] = &machines.Items[i]
+	}
+
+	pools := map[string]*pool{}
+	var order []string
+	for _, n := range nodes {
+		name := workerPool
+		tkr := cluster.GetLabels()[kinds.TanzuKubernetesReleaseLabel]
+		if m, ok := byNode[n.Name]; ok {
+			ml := m.GetLabels()
+			if _, cp := ml["cluster.x-k8s.io/control-plane"]; cp {
+				name = controlPlanePool
+			} else if md := ml["cluster.x-k8s.io/deployment-name"]; md != "" {
+				name = md
+			}
+			// A pool may run a different TanzuKubernetesRelease than the
+			// Cluster while it is being upgraded or if it is overridden.
+			if r := ml[kinds.TanzuKubernetesReleaseLabel]; r != "" {
+				tkr = r
+			}
+		}
+
+		p, ok := pools[name]
+		if !ok {
+			p = &pool{name: name, tkr: tkr}
+			pools[name] = p
+			order = append(order, name)
+		}
+		p.nodes = append(p.nodes, n)
+	}
+
+	sort.Strings(order)
+	result := make([]*pool, 0, len(order))
+	for _, name := range order {
+		result = append(result, pools[name])
+	}
+	return result, nil
+}
+
+// assertConditions checks that each expected condition of the node has the
+// healthy status.
+func assertConditions(t *testing.T, n *corev1.Node) {
+	t.Helper()
+
+	for _, expected := range expectedConditions {
+		var found *corev1.NodeCondition
+		for i := range n.Status.Conditions {
+			if n.Status.Conditions[i].Type == expected.condType {
+				found = &n.Status.Conditions[i]
+				break
+			}
+		}
+
+		switch {
+		case found == nil && expected.required:
+			t.Errorf("node %s does not report condition %s", n.Name, expected.condType)
+		case found != nil && found.Status != expected.status:
+			t.Errorf("node %s condition %s is %s, expected %s: %s: %s",
+				n.Name, found.Type, found.Status, expected.status, found.Reason, found.Message)
+		}
+	}
+}
+
+// mostCommon returns the value shared by the most keys, breaking ties by the
+// smallest value so that the result is stable.
+func mostCommon(values map[string]string) string {
+	counts := map[string]int{}
+	for _, v := range values {
+		counts[v]++
+	}
+
+	var common string
+	for v, n := range counts {
+		if n > counts[common] || (n == counts[common] && v < common) {
+			common = v
+		}
+	}
+	return common
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/version"
+	"sigs.k8s.io/e2e-framework/klient"
+
+	"github.com/tvs/kubernetes-service-tests/test/e2e/framework/kinds"
+)
+
+// osImage is the operating system an OSImage of a TanzuKubernetesRelease
+// provides.
+type osImage struct {
+	name    string
+	version string
+}
+
+func (o osImage) String() string {
+	return fmt.Sprintf("%s %s", o.name, o.version)
+}
+
+// matches reports whether the OS image reported by a node, such as
+// "Ubuntu 22.04.4 LTS", is this operating system. Not every OS reports its
+// version, Photon OS reports "VMware Photon OS/Linux" for instance, so the
+// version is only compared when the node reports one, and then by release
+// so that "22.04" matches "22.04.4" but not "22.040".
+func (o osImage) matches(reported string) bool {
+	if !strings.Contains(strings.ToLower(reported), strings.ToLower(o.name)) {
+		return false
+	}
+
+	for _, field := range strings.Fields(reported) {
+		if !strings.ContainsFunc(field, unicode.IsDigit) || strings.Trim(field, "0123456789.") != "" {
+			continue
+		}
+		return field == o.version || strings.HasPrefix(field, o.version+".")
+	}
+	return true
+}
+
+// assertMatchesRelease checks that the kubelet of every node in the pool runs
+// the Kubernetes version of the pool's TanzuKubernetesRelease and that its OS
+// image is one of the release's OSImages.
+func assertMatchesRelease(ctx context.Context, t *testing.T, supervisor klient.Client, p *pool) {
+	t.Helper()
+
+	if p.tkr == "" {
+		t.Errorf("unable to determine the TanzuKubernetesRelease of pool %s", p.name)
+		return
+	}
+
+	tkr := kinds.New(kinds.TanzuKubernetesRelease)
+	if err := supervisor.Resources().Get(ctx, p.tkr, "", tkr); err != nil {
+		t.Errorf("unable to get TanzuKubernetesRelease %s of pool %s: %s", p.tkr, p.name, err)
+		return
+	}
+
+	raw, _, _ := unstructured.NestedString(tkr.Object, "spec", "kubernetes", "version")
+	expected, err := version.ParseSemantic(raw)
+	if err != nil {
+		t.Errorf("TanzuKubernetesRelease %s has invalid version %q: %s", p.tkr, raw, err)
+		return
+	}
+
+	images, err := osImages(ctx, supervisor, tkr)
+	if err != nil {
+		t.Errorf("unable to get OSImages of TanzuKubernetesRelease %s: %s", p.tkr, err)
+		return
+	}
+
+	for _, n := range p.nodes {
+		info := n.Status.NodeInfo
+
+		// Only the release is compared, as the kubelet does not report the
+		// distribution build metadata of the TanzuKubernetesRelease.
+		kubelet, err := version.ParseSemantic(info.KubeletVersion)
+		switch {
+		case err != nil:
+			t.Errorf("node %s in pool %s has invalid kubelet version %q: %s", n.Name, p.name, info.KubeletVersion, err)
+		case kubelet.Major() != expected.Major() || kubelet.Minor() != expected.Minor() || kubelet.Patch() != expected.Patch():
+			t.Errorf("node %s in pool %s has kubelet version %s, TanzuKubernetesRelease %s has %s",
+				n.Name, p.name, info.KubeletVersion, p.tkr, raw)
+		}
+
+		found := false
+		for _, img := range images {
+			if img.matches(info.OSImage) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("node %s in pool %s has OS image %q, TanzuKubernetesRelease %s provides %v",
+				n.Name, p.name, info.OSImage, p.tkr, images)
+		}
+	}
+}
+
+// osImages returns the operating systems of the OSImages referenced by the
+// TanzuKubernetesRelease.
+func osImages(ctx context.Context, supervisor klient.Client, tkr *unstructured.Unstructured) ([]osImage, error) {
+	refs, _, _ := unstructured.NestedSlice(tkr.Object, "spec", "osImages")
+
+	images := make([]osImage, 0, len(refs))
+	for _, raw := range refs {
+		ref, _ := raw.(map[string]interface{})
+		name, _, _ := unstructured.NestedString(ref, "name")
+
+		img := kinds.New(kinds.OSImage)
+		if err := supervisor.Resources().Get(ctx, name, "", img); err != nil {
+			return nil, fmt.Errorf("getting OSImage %q: %w", name, err)
+		}
+
+		var o osImage
+		o.name, _, _ = unstructured.NestedString(img.Object, "spec", "os", "name")
+		o.version, _, _ = unstructured.NestedString(img.Object, "spec", "os", "version")
+		images = append(images, o)
+	}
+	return images, nil
+}
@@ -24,15 +24,30 @@ import (
 	"github.com/tvs/kubernetes-service-tests/test/e2e/framework"
 	"github.com/tvs/kubernetes-service-tests/test/e2e/workload/features/addons/cloudprovider"
 	"github.com/tvs/kubernetes-service-tests/test/e2e/workload/features/addons/cni"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/workload/features/addons/csi"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/workload/features/addons/dns"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/workload/features/addons/metrics"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/workload/features/certificates"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/workload/features/controlplane"
+	"github.com/tvs/kubernetes-service-tests/test/e2e/workload/features/nodes"
 )
 
 func WorkloadClusterTests(t *testing.T, tc *framework.TestContextType) {
 	builder := framework.NewTestRunner()
 
 	feat := []features.Feature{}
+	feat = append(feat, controlplane.Features(t, tc)...)
+	feat = append(feat, nodes.Features(t, tc)...)
+	feat = append(feat, certificates.Features(t, tc)...)
 	feat = append(feat, cni.Features(t, tc)...)
 	feat = append(feat, cloudprovider.Features(t, tc)...)
+	feat = append(feat, csi.Features(t, tc)...)
+	feat = append(feat, dns.Features(t, tc)...)
+	feat = append(feat, metrics.Features(t, tc)...)
 	builder.WithParallelSequence(feat...)
 
+	// Slow features are not allowed in a parallel sequence.
+	builder.WithSerialSequence(csi.SerialFeatures(t, tc)...)
+
 	builder.Runner().Test(t, tc)
 }